@@ -0,0 +1,98 @@
+// Package cfattrs provides constructors for the cf.* attributes shared by
+// Common Fate services, so that the attributes have the same keys and
+// format in every service and can be queried together in the backend.
+//
+// Values which are not valid for an attribute are still recorded, and are
+// reported to the global OpenTelemetry error handler.
+package cfattrs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Keys of the Common Fate attributes.
+const (
+	TenantIDKey        = attribute.Key("cf.tenant_id")
+	GrantIDKey         = attribute.Key("cf.grant_id")
+	AccessRequestIDKey = attribute.Key("cf.access_request_id")
+	ProviderKey        = attribute.Key("cf.provider")
+)
+
+// maxIDLength is the maximum length of an identifier.
+const maxIDLength = 128
+
+var (
+	idPattern       = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.:-]*$`)
+	providerPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+)
+
+// TenantID returns the attribute identifying the tenant a span or
+// measurement belongs to.
+func TenantID(id string) attribute.KeyValue {
+	return checked(TenantIDKey.String(id))
+}
+
+// GrantID returns the attribute identifying a grant.
+func GrantID(id string) attribute.KeyValue {
+	return checked(GrantIDKey.String(id))
+}
+
+// AccessRequestID returns the attribute identifying an access request.
+func AccessRequestID(id string) attribute.KeyValue {
+	return checked(AccessRequestIDKey.String(id))
+}
+
+// Provider returns the attribute identifying an access provider, such as
+// "okta" or "aws-sso". Provider names are lower case.
+func Provider(name string) attribute.KeyValue {
+	return checked(ProviderKey.String(name))
+}
+
+func checked(kv attribute.KeyValue) attribute.KeyValue {
+	if err := Validate(kv); err != nil {
+		otel.Handle(err)
+	}
+	return kv
+}
+
+// Validate checks that attributes in the cf.* namespace have a known key
+// and a valid value. Attributes in other namespaces are ignored.
+func Validate(attrs ...attribute.KeyValue) error {
+	for _, kv := range attrs {
+		if !strings.HasPrefix(string(kv.Key), "cf.") {
+			continue
+		}
+		if err := validate(kv); err != nil {
+			return fmt.Errorf("invalid %s attribute: %w", kv.Key, err)
+		}
+	}
+	return nil
+}
+
+func validate(kv attribute.KeyValue) error {
+	var pattern *regexp.Regexp
+	switch kv.Key {
+	case TenantIDKey, GrantIDKey, AccessRequestIDKey:
+		pattern = idPattern
+	case ProviderKey:
+		pattern = providerPattern
+	default:
+		return fmt.Errorf("unknown key")
+	}
+	if kv.Value.Type() != attribute.STRING {
+		return fmt.Errorf("value must be a string, not %s", kv.Value.Type())
+	}
+	v := kv.Value.AsString()
+	if len(v) > maxIDLength {
+		return fmt.Errorf("value is longer than %d characters", maxIDLength)
+	}
+	if !pattern.MatchString(v) {
+		return fmt.Errorf("%q does not match %s", v, pattern)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package cfattrs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(
+		TenantID("tnt_2a8f"),
+		GrantID("gra_01FQ7K"),
+		AccessRequestID("req-123"),
+		Provider("aws-sso"),
+		attribute.String("http.method", "GET"),
+	))
+
+	for _, kv := range []attribute.KeyValue{
+		TenantIDKey.String(""),
+		GrantIDKey.String("gra 01"),
+		ProviderKey.String("Okta"),
+		AccessRequestIDKey.Int(123),
+		attribute.String("cf.customer", "acme"),
+	} {
+		assert.Error(t, Validate(kv), kv.Key)
+	}
+}
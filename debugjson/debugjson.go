@@ -0,0 +1,216 @@
+// Package debugjson encodes spans and metric records as indented JSON for
+// humans to read, such as when debugging instrumentation locally. All
+// human-facing telemetry output in this module should use this format, so
+// that it is consistent wherever it is printed.
+//
+// The format is stable: fields are only ever added. Attribute and resource
+// maps are encoded with their keys sorted.
+package debugjson
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Span is the JSON representation of an ended span.
+type Span struct {
+	Name                   string                 `json:"name"`
+	TraceID                string                 `json:"trace_id"`
+	SpanID                 string                 `json:"span_id"`
+	ParentSpanID           string                 `json:"parent_span_id,omitempty"`
+	Kind                   string                 `json:"kind"`
+	StartTime              time.Time              `json:"start_time"`
+	EndTime                time.Time              `json:"end_time"`
+	Duration               string                 `json:"duration"`
+	Status                 Status                 `json:"status"`
+	Attributes             map[string]interface{} `json:"attributes,omitempty"`
+	Events                 []Event                `json:"events,omitempty"`
+	Links                  []Link                 `json:"links,omitempty"`
+	DroppedAttributes      int                    `json:"dropped_attributes,omitempty"`
+	DroppedEvents          int                    `json:"dropped_events,omitempty"`
+	DroppedLinks           int                    `json:"dropped_links,omitempty"`
+	InstrumentationLibrary string                 `json:"instrumentation_library,omitempty"`
+	Resource               map[string]interface{} `json:"resource,omitempty"`
+}
+
+// Status is the JSON representation of a span status.
+type Status struct {
+	Code        string `json:"code"`
+	Description string `json:"description,omitempty"`
+}
+
+// Event is the JSON representation of a span event.
+type Event struct {
+	Name       string                 `json:"name"`
+	Time       time.Time              `json:"time"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// Link is the JSON representation of a span link.
+type Link struct {
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// Metric is the JSON representation of an exported metric record.
+type Metric struct {
+	Name                   string                 `json:"name"`
+	Description            string                 `json:"description,omitempty"`
+	Unit                   string                 `json:"unit,omitempty"`
+	InstrumentKind         string                 `json:"instrument_kind"`
+	Aggregation            string                 `json:"aggregation"`
+	StartTime              time.Time              `json:"start_time"`
+	EndTime                time.Time              `json:"end_time"`
+	Attributes             map[string]interface{} `json:"attributes,omitempty"`
+	Sum                    interface{}            `json:"sum,omitempty"`
+	Count                  *uint64                `json:"count,omitempty"`
+	LastValue              interface{}            `json:"last_value,omitempty"`
+	Boundaries             []float64              `json:"boundaries,omitempty"`
+	BucketCounts           []uint64               `json:"bucket_counts,omitempty"`
+	InstrumentationLibrary string                 `json:"instrumentation_library,omitempty"`
+	Error                  string                 `json:"error,omitempty"`
+}
+
+// FromSpan returns the JSON representation of s.
+func FromSpan(s sdktrace.ReadOnlySpan) Span {
+	sc := s.SpanContext()
+	span := Span{
+		Name:      s.Name(),
+		TraceID:   sc.TraceID().String(),
+		SpanID:    sc.SpanID().String(),
+		Kind:      s.SpanKind().String(),
+		StartTime: s.StartTime(),
+		EndTime:   s.EndTime(),
+		Duration:  s.EndTime().Sub(s.StartTime()).String(),
+		Status: Status{
+			Code:        s.Status().Code.String(),
+			Description: s.Status().Description,
+		},
+		Attributes:             attributes(s.Attributes()),
+		DroppedAttributes:      s.DroppedAttributes(),
+		DroppedEvents:          s.DroppedEvents(),
+		DroppedLinks:           s.DroppedLinks(),
+		InstrumentationLibrary: s.InstrumentationLibrary().Name,
+		Resource:               resourceAttributes(s.Resource()),
+	}
+	if parent := s.Parent(); parent.HasSpanID() {
+		span.ParentSpanID = parent.SpanID().String()
+	}
+	for _, e := range s.Events() {
+		span.Events = append(span.Events, Event{
+			Name:       e.Name,
+			Time:       e.Time,
+			Attributes: attributes(e.Attributes),
+		})
+	}
+	for _, l := range s.Links() {
+		span.Links = append(span.Links, Link{
+			TraceID:    l.SpanContext.TraceID().String(),
+			SpanID:     l.SpanContext.SpanID().String(),
+			Attributes: attributes(l.Attributes),
+		})
+	}
+	return span
+}
+
+// FromRecord returns the JSON representation of a metric record exported
+// by lib. An aggregation which cannot be read is reported in Error.
+func FromRecord(lib instrumentation.Library, r metric.Record) Metric {
+	desc := r.Descriptor()
+	m := Metric{
+		Name:                   desc.Name(),
+		Description:            desc.Description(),
+		Unit:                   string(desc.Unit()),
+		InstrumentKind:         desc.InstrumentKind().String(),
+		StartTime:              r.StartTime(),
+		EndTime:                r.EndTime(),
+		Attributes:             attributes(r.Labels().ToSlice()),
+		InstrumentationLibrary: lib.Name,
+	}
+	agg := r.Aggregation()
+	if agg == nil {
+		return m
+	}
+	m.Aggregation = string(agg.Kind())
+	kind := desc.NumberKind()
+	var err error
+	switch a := agg.(type) {
+	case aggregation.Histogram:
+		var buckets aggregation.Buckets
+		if buckets, err = a.Histogram(); err == nil {
+			m.Boundaries = buckets.Boundaries
+			m.BucketCounts = buckets.Counts
+		}
+		if count, cerr := a.Count(); cerr == nil {
+			m.Count = &count
+		}
+		if sum, serr := a.Sum(); serr == nil {
+			m.Sum = sum.AsInterface(kind)
+		}
+	case aggregation.LastValue:
+		value, _, lerr := a.LastValue()
+		if err = lerr; err == nil {
+			m.LastValue = value.AsInterface(kind)
+		}
+	case aggregation.Sum:
+		sum, serr := a.Sum()
+		if err = serr; err == nil {
+			m.Sum = sum.AsInterface(kind)
+		}
+	}
+	if err != nil {
+		m.Error = err.Error()
+	}
+	return m
+}
+
+// Encoder writes spans and metric records to a stream as indented JSON
+// values.
+type Encoder struct {
+	enc *json.Encoder
+}
+
+// NewEncoder returns an encoder which writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return &Encoder{enc: enc}
+}
+
+// EncodeSpan writes the JSON representation of s.
+func (e *Encoder) EncodeSpan(s sdktrace.ReadOnlySpan) error {
+	return e.enc.Encode(FromSpan(s))
+}
+
+// EncodeRecord writes the JSON representation of a metric record exported
+// by lib.
+func (e *Encoder) EncodeRecord(lib instrumentation.Library, r metric.Record) error {
+	return e.enc.Encode(FromRecord(lib, r))
+}
+
+func attributes(kvs []attribute.KeyValue) map[string]interface{} {
+	if len(kvs) == 0 {
+		return nil
+	}
+	m := make(map[string]interface{}, len(kvs))
+	for _, kv := range kvs {
+		m[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	return m
+}
+
+func resourceAttributes(r *resource.Resource) map[string]interface{} {
+	if r == nil {
+		return nil
+	}
+	return attributes(r.Attributes())
+}
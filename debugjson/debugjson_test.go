@@ -0,0 +1,67 @@
+package debugjson
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestEncodeSpan(t *testing.T) {
+	start := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	span := tracetest.SpanStub{
+		Name: "GET /users",
+		SpanContext: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: [16]byte{1},
+			SpanID:  [8]byte{2},
+		}),
+		SpanKind:   trace.SpanKindServer,
+		StartTime:  start,
+		EndTime:    start.Add(1500 * time.Millisecond),
+		Attributes: []attribute.KeyValue{attribute.String("route", "/users"), attribute.Int("http.status_code", 500)},
+		Events: []sdktrace.Event{{
+			Name: "retry",
+			Time: start.Add(time.Second),
+		}},
+		Status:   sdktrace.Status{Code: codes.Error, Description: "internal error"},
+		Resource: resource.NewSchemaless(attribute.String("service.name", "api")),
+	}.Snapshot()
+
+	var buf bytes.Buffer
+	require.NoError(t, NewEncoder(&buf).EncodeSpan(span))
+	assert.Equal(t, `{
+  "name": "GET /users",
+  "trace_id": "01000000000000000000000000000000",
+  "span_id": "0200000000000000",
+  "kind": "server",
+  "start_time": "2022-01-02T03:04:05Z",
+  "end_time": "2022-01-02T03:04:06.5Z",
+  "duration": "1.5s",
+  "status": {
+    "code": "Error",
+    "description": "internal error"
+  },
+  "attributes": {
+    "http.status_code": 500,
+    "route": "/users"
+  },
+  "events": [
+    {
+      "name": "retry",
+      "time": "2022-01-02T03:04:06Z"
+    }
+  ],
+  "resource": {
+    "service.name": "api"
+  }
+}
+`, buf.String())
+}
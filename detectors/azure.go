@@ -0,0 +1,93 @@
+package detectors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	semconv "go.opentelemetry.io/collector/model/semconv/v1.5.0"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+const azureMetadataEndpoint = "http://169.254.169.254/metadata/instance/compute?api-version=2021-02-01&format=json"
+
+// Attributes describing Azure VMs, which are not defined by the semantic
+// conventions. The keys match those of the OpenTelemetry Collector's Azure
+// resource detector.
+const (
+	AttributeAzureVMName            = "azure.vm.name"
+	AttributeAzureVMSize            = "azure.vm.size"
+	AttributeAzureVMScaleSetName    = "azure.vm.scaleset.name"
+	AttributeAzureResourceGroupName = "azure.resourcegroup.name"
+)
+
+type azureDetector struct {
+	client   *http.Client
+	endpoint string
+}
+
+// Azure returns a detector which populates the cloud and host attributes
+// of an Azure VM, including the nodes of an AKS cluster, from the Azure
+// instance metadata service.
+func Azure() resource.Detector {
+	return &azureDetector{
+		client:   &http.Client{Timeout: metadataTimeout},
+		endpoint: azureMetadataEndpoint,
+	}
+}
+
+type azureComputeMetadata struct {
+	Location          string `json:"location"`
+	Zone              string `json:"zone"`
+	Name              string `json:"name"`
+	VMID              string `json:"vmId"`
+	VMSize            string `json:"vmSize"`
+	VMScaleSetName    string `json:"vmScaleSetName"`
+	SubscriptionID    string `json:"subscriptionId"`
+	ResourceGroupName string `json:"resourceGroupName"`
+}
+
+func (d *azureDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata", "true")
+	res, err := d.client.Do(req)
+	if err != nil {
+		// The metadata service is unreachable outside Azure.
+		return resource.Empty(), nil
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return resource.Empty(), nil
+	}
+	var compute azureComputeMetadata
+	if err := json.NewDecoder(res.Body).Decode(&compute); err != nil {
+		return nil, fmt.Errorf("failed to read Azure instance metadata: %w", err)
+	}
+
+	platform := semconv.AttributeCloudPlatformAzureVM
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		platform = semconv.AttributeCloudPlatformAzureAKS
+	}
+	attrs := appendNonEmpty([]attribute.KeyValue{
+		attribute.String(semconv.AttributeCloudProvider, semconv.AttributeCloudProviderAzure),
+		attribute.String(semconv.AttributeCloudPlatform, platform),
+	},
+		attribute.String(semconv.AttributeCloudRegion, compute.Location),
+		attribute.String(semconv.AttributeCloudAvailabilityZone, compute.Zone),
+		attribute.String(semconv.AttributeCloudAccountID, compute.SubscriptionID),
+		attribute.String(semconv.AttributeHostID, compute.VMID),
+		attribute.String(semconv.AttributeHostName, compute.Name),
+		attribute.String(semconv.AttributeHostType, compute.VMSize),
+		attribute.String(AttributeAzureVMName, compute.Name),
+		attribute.String(AttributeAzureVMSize, compute.VMSize),
+		attribute.String(AttributeAzureVMScaleSetName, compute.VMScaleSetName),
+		attribute.String(AttributeAzureResourceGroupName, compute.ResourceGroupName),
+	)
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
@@ -0,0 +1,37 @@
+package detectors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestAzureDetector(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata") != "true" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"location":"australiaeast","name":"api-vm","vmId":"02aab8a4","vmSize":"Standard_D2s_v3","subscriptionId":"8d10da13","resourceGroupName":"prod"}`))
+	}))
+	defer srv.Close()
+
+	d := &azureDetector{client: srv.Client(), endpoint: srv.URL}
+	r, err := d.Detect(context.Background())
+	require.NoError(t, err)
+	attrs := r.Attributes()
+	assert.Contains(t, attrs, attribute.String("cloud.provider", "azure"))
+	assert.Contains(t, attrs, attribute.String("cloud.region", "australiaeast"))
+	assert.Contains(t, attrs, attribute.String("host.id", "02aab8a4"))
+	assert.Contains(t, attrs, attribute.String("azure.resourcegroup.name", "prod"))
+
+	srv.Close()
+	r, err = d.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, r.Len())
+}
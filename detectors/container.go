@@ -0,0 +1,95 @@
+package detectors
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"regexp"
+
+	semconv "go.opentelemetry.io/collector/model/semconv/v1.5.0"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+const (
+	cgroupPath    = "/proc/self/cgroup"
+	mountInfoPath = "/proc/self/mountinfo"
+)
+
+// containerIDEnvVars are the environment variables which may explicitly
+// set the container ID, such as from a containerd or Docker label, for
+// runtimes whose IDs can't be read from the cgroups or mounts.
+var containerIDEnvVars = []string{"CONTAINER_ID", "DOCKER_CONTAINER_ID", "CONTAINERD_CONTAINER_ID"}
+
+var (
+	// cgroupContainerIDRe matches the container ID at the end of a cgroup
+	// v1 path, as written by Docker, containerd and CRI-O, e.g.
+	// "/docker/<id>", "/kubepods/pod<uid>/<id>" or "/cri-containerd-<id>.scope".
+	cgroupContainerIDRe = regexp.MustCompile(`([0-9a-f]{64})(?:\.scope)?$`)
+	// mountInfoContainerIDRe matches the container ID in the path of the
+	// hostname or resolv.conf bind mounts, which remain visible under
+	// cgroup v2 where /proc/self/cgroup only contains "0::/".
+	mountInfoContainerIDRe = regexp.MustCompile(`/(?:docker/containers|containers|sandboxes)/([0-9a-f]{64})/`)
+)
+
+type containerDetector struct {
+	cgroupFile    string
+	mountInfoFile string
+}
+
+// Container returns a detector which populates container.id when running
+// in a Docker, containerd or CRI-O container, from the CONTAINER_ID,
+// DOCKER_CONTAINER_ID or CONTAINERD_CONTAINER_ID environment variables if
+// set, and otherwise from the process cgroups or, under cgroup v2, its
+// mounts.
+func Container() resource.Detector {
+	return &containerDetector{cgroupFile: cgroupPath, mountInfoFile: mountInfoPath}
+}
+
+func (d *containerDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	id := containerIDFromEnv()
+	if id == "" {
+		id = containerID(d.cgroupFile, d.mountInfoFile)
+	}
+	if id == "" {
+		return resource.Empty(), nil
+	}
+	return resource.NewWithAttributes(semconv.SchemaURL, attribute.String(semconv.AttributeContainerID, id)), nil
+}
+
+// containerIDFromEnv returns the container ID set in the environment, or
+// an empty string if it isn't set.
+func containerIDFromEnv() string {
+	for _, key := range containerIDEnvVars {
+		if id := os.Getenv(key); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// containerID returns the ID of the container the process is running in,
+// or an empty string if it can't be determined.
+func containerID(cgroupFile, mountInfoFile string) string {
+	if id := findSubmatch(cgroupFile, cgroupContainerIDRe); id != "" {
+		return id
+	}
+	return findSubmatch(mountInfoFile, mountInfoContainerIDRe)
+}
+
+// findSubmatch returns the first submatch of re in the lines of the file.
+func findSubmatch(path string, re *regexp.Regexp) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if m := re.FindStringSubmatch(scanner.Text()); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,7 @@
+// Package detectors provides OpenTelemetry resource detectors for the
+// environments Common Fate services are deployed to. The detectors can be
+// enabled through launcher options, or passed to resource.New directly.
+//
+// Detectors return an empty resource, rather than an error, when the
+// process is not running in the environment they detect.
+package detectors
@@ -0,0 +1,136 @@
+package detectors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	semconv "go.opentelemetry.io/collector/model/semconv/v1.5.0"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+const (
+	ecsMetadataV4EnvVar = "ECS_CONTAINER_METADATA_URI_V4"
+	ecsMetadataV3EnvVar = "ECS_CONTAINER_METADATA_URI"
+)
+
+// metadataTimeout bounds each request to a metadata endpoint so that
+// detection can't delay startup indefinitely.
+const metadataTimeout = 2 * time.Second
+
+type ecsDetector struct {
+	client *http.Client
+}
+
+// ECS returns a detector which populates the container, task and cluster
+// attributes of an ECS task from the ECS task metadata endpoint.
+func ECS() resource.Detector {
+	return &ecsDetector{client: &http.Client{Timeout: metadataTimeout}}
+}
+
+type ecsContainerMetadata struct {
+	Name         string `json:"Name"`
+	DockerID     string `json:"DockerId"`
+	ContainerARN string `json:"ContainerARN"`
+	Image        string `json:"Image"`
+}
+
+type ecsTaskMetadata struct {
+	Cluster          string `json:"Cluster"`
+	TaskARN          string `json:"TaskARN"`
+	Family           string `json:"Family"`
+	Revision         string `json:"Revision"`
+	LaunchType       string `json:"LaunchType"`
+	AvailabilityZone string `json:"AvailabilityZone"`
+}
+
+func (d *ecsDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	uri := os.Getenv(ecsMetadataV4EnvVar)
+	if uri == "" {
+		uri = os.Getenv(ecsMetadataV3EnvVar)
+	}
+	if uri == "" {
+		return resource.Empty(), nil
+	}
+
+	var container ecsContainerMetadata
+	if err := getJSON(ctx, d.client, uri, nil, &container); err != nil {
+		return nil, fmt.Errorf("failed to read ECS container metadata: %w", err)
+	}
+	var task ecsTaskMetadata
+	if err := getJSON(ctx, d.client, uri+"/task", nil, &task); err != nil {
+		return nil, fmt.Errorf("failed to read ECS task metadata: %w", err)
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String(semconv.AttributeCloudProvider, semconv.AttributeCloudProviderAWS),
+		attribute.String(semconv.AttributeCloudPlatform, semconv.AttributeCloudPlatformAWSECS),
+	}
+	attrs = appendNonEmpty(attrs,
+		attribute.String(semconv.AttributeContainerName, container.Name),
+		attribute.String(semconv.AttributeContainerID, container.DockerID),
+		attribute.String(semconv.AttributeAWSECSContainerARN, container.ContainerARN),
+		attribute.String(semconv.AttributeAWSECSTaskARN, task.TaskARN),
+		attribute.String(semconv.AttributeAWSECSClusterARN, ecsClusterARN(task.Cluster, task.TaskARN)),
+		attribute.String(semconv.AttributeAWSECSLaunchtype, strings.ToLower(task.LaunchType)),
+		attribute.String(semconv.AttributeAWSECSTaskFamily, task.Family),
+		attribute.String(semconv.AttributeAWSECSTaskRevision, task.Revision),
+		attribute.String(semconv.AttributeCloudAvailabilityZone, task.AvailabilityZone),
+	)
+	if arn := strings.Split(task.TaskARN, ":"); len(arn) >= 5 {
+		attrs = appendNonEmpty(attrs,
+			attribute.String(semconv.AttributeCloudRegion, arn[3]),
+			attribute.String(semconv.AttributeCloudAccountID, arn[4]),
+		)
+	}
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
+
+// ecsClusterARN returns the ARN of the cluster. The metadata endpoint
+// reports either the cluster ARN or just its name, in which case the ARN is
+// derived from the task ARN.
+func ecsClusterARN(cluster, taskARN string) string {
+	if cluster == "" || strings.HasPrefix(cluster, "arn:") {
+		return cluster
+	}
+	i := strings.LastIndex(taskARN, ":task/")
+	if i < 0 {
+		return cluster
+	}
+	return taskARN[:i] + ":cluster/" + cluster
+}
+
+// getJSON decodes the JSON response to a GET request for url into v.
+func getJSON(ctx context.Context, client *http.Client, url string, header http.Header, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	for k, vals := range header {
+		req.Header[k] = vals
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status from %s: %s", url, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// appendNonEmpty appends the attributes which have a non-empty value.
+func appendNonEmpty(attrs []attribute.KeyValue, kvs ...attribute.KeyValue) []attribute.KeyValue {
+	for _, kv := range kvs {
+		if kv.Value.AsString() != "" {
+			attrs = append(attrs, kv)
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,47 @@
+package detectors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestECSDetector(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v4/abc", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"Name":"api","DockerId":"c0ffee","ContainerARN":"arn:aws:ecs:us-west-2:111122223333:container/abc"}`))
+	})
+	mux.HandleFunc("/v4/abc/task", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"Cluster":"prod","TaskARN":"arn:aws:ecs:us-west-2:111122223333:task/prod/123","Family":"api","Revision":"7","LaunchType":"FARGATE","AvailabilityZone":"us-west-2a"}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	defer os.Unsetenv(ecsMetadataV4EnvVar)
+	os.Setenv(ecsMetadataV4EnvVar, srv.URL+"/v4/abc")
+
+	r, err := ECS().Detect(context.Background())
+	require.NoError(t, err)
+	attrs := r.Attributes()
+	assert.Contains(t, attrs, attribute.String("container.name", "api"))
+	assert.Contains(t, attrs, attribute.String("container.id", "c0ffee"))
+	assert.Contains(t, attrs, attribute.String("aws.ecs.task.arn", "arn:aws:ecs:us-west-2:111122223333:task/prod/123"))
+	assert.Contains(t, attrs, attribute.String("aws.ecs.cluster.arn", "arn:aws:ecs:us-west-2:111122223333:cluster/prod"))
+	assert.Contains(t, attrs, attribute.String("aws.ecs.launchtype", "fargate"))
+	assert.Contains(t, attrs, attribute.String("cloud.region", "us-west-2"))
+}
+
+func TestECSDetectorNotOnECS(t *testing.T) {
+	os.Unsetenv(ecsMetadataV4EnvVar)
+	os.Unsetenv(ecsMetadataV3EnvVar)
+
+	r, err := ECS().Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, r.Len())
+}
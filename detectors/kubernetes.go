@@ -0,0 +1,100 @@
+package detectors
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	semconv "go.opentelemetry.io/collector/model/semconv/v1.5.0"
+	"go.opentelemetry.io/contrib/detectors/aws/eks"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+const serviceAccountNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+type kubernetesDetector struct {
+	eks           resource.Detector
+	namespaceFile string
+	cgroupFile    string
+	mountInfoFile string
+}
+
+// Kubernetes returns a detector which populates the cluster, namespace,
+// pod and container attributes when running in Kubernetes, including EKS.
+//
+// On EKS the cluster name, cloud platform and container ID are detected
+// with the contrib EKS detector, which reads the cluster name from the
+// Kubernetes API and requires permission to read the aws-auth and
+// cluster-info config maps in kube-system. The remaining attributes, and
+// any the EKS detector could not detect, are read from environment
+// variables, which are expected to be populated with the downward API:
+//
+//	env:
+//	  - name: K8S_CLUSTER_NAME
+//	    value: prod
+//	  - name: K8S_POD_NAME
+//	    valueFrom: {fieldRef: {fieldPath: metadata.name}}
+//	  - name: K8S_NAMESPACE
+//	    valueFrom: {fieldRef: {fieldPath: metadata.namespace}}
+//
+// When they are missing, the namespace falls back to the service account
+// namespace, the pod name to the hostname, and the container ID is read
+// from the process cgroups. Outside EKS the cluster name can't be
+// discovered without access to the Kubernetes API, so it must be set
+// explicitly.
+func Kubernetes() resource.Detector {
+	return &kubernetesDetector{
+		eks:           eks.NewResourceDetector(),
+		namespaceFile: serviceAccountNamespacePath,
+		cgroupFile:    cgroupPath,
+		mountInfoFile: mountInfoPath,
+	}
+}
+
+func (d *kubernetesDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
+		return resource.Empty(), nil
+	}
+
+	namespace := firstEnv("K8S_NAMESPACE", "POD_NAMESPACE")
+	if namespace == "" {
+		if b, err := ioutil.ReadFile(d.namespaceFile); err == nil {
+			namespace = strings.TrimSpace(string(b))
+		}
+	}
+	pod := firstEnv("K8S_POD_NAME", "POD_NAME")
+	if pod == "" {
+		pod, _ = os.Hostname()
+	}
+
+	attrs := appendNonEmpty(nil,
+		attribute.String(semconv.AttributeK8SClusterName, firstEnv("K8S_CLUSTER_NAME", "CLUSTER_NAME")),
+		attribute.String(semconv.AttributeK8SNamespaceName, namespace),
+		attribute.String(semconv.AttributeK8SPodName, pod),
+		attribute.String(semconv.AttributeK8SPodUID, firstEnv("K8S_POD_UID", "POD_UID")),
+		attribute.String(semconv.AttributeK8SNodeName, firstEnv("K8S_NODE_NAME", "NODE_NAME")),
+		attribute.String(semconv.AttributeK8SContainerName, firstEnv("K8S_CONTAINER_NAME", "CONTAINER_NAME")),
+		attribute.String(semconv.AttributeContainerID, containerID(d.cgroupFile, d.mountInfoFile)),
+	)
+	// The attributes detected on EKS take precedence over the fallbacks.
+	// The EKS detector fails without access to the Kubernetes API, in
+	// which case only the fallbacks are used.
+	if d.eks != nil {
+		if r, err := d.eks.Detect(ctx); err == nil && r != nil {
+			attrs = append(attrs, r.Attributes()...)
+		}
+	}
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
+
+// firstEnv returns the value of the first environment variable which is set.
+func firstEnv(keys ...string) string {
+	for _, k := range keys {
+		if v := os.Getenv(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,113 @@
+package detectors
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+const testContainerID = "7be92808767a667f35c8505cbf40d14e931ef6db5b0210329cf193b15ba9d605"
+
+func writeFile(t *testing.T, dir, name, contents string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+	return path
+}
+
+func TestContainerID(t *testing.T) {
+	dir, err := ioutil.TempDir("", "detectors")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, line := range []string{
+		"12:pids:/docker/" + testContainerID,
+		"11:memory:/kubepods/besteffort/pod2c8d2f4e-1f4f-4b7a-a8d5-5b1f1b7e4a6a/" + testContainerID,
+		"0::/system.slice/cri-containerd-" + testContainerID + ".scope",
+	} {
+		cgroup := writeFile(t, dir, "cgroup", "13:cpuset:/\n"+line+"\n")
+		assert.Equal(t, testContainerID, containerID(cgroup, ""), line)
+	}
+
+	cgroup := writeFile(t, dir, "cgroup", "0::/\n")
+	mountInfo := writeFile(t, dir, "mountinfo", "1 0 0:1 /var/lib/docker/containers/"+testContainerID+"/hostname /etc/hostname rw\n")
+	assert.Equal(t, testContainerID, containerID(cgroup, mountInfo))
+	assert.Equal(t, "", containerID(cgroup, ""))
+
+	r, err := (&containerDetector{cgroupFile: cgroup, mountInfoFile: mountInfo}).Detect(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, r.Attributes(), attribute.String("container.id", testContainerID))
+
+	os.Setenv("CONTAINERD_CONTAINER_ID", "from-env")
+	defer os.Unsetenv("CONTAINERD_CONTAINER_ID")
+	r, err = (&containerDetector{cgroupFile: cgroup, mountInfoFile: mountInfo}).Detect(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, r.Attributes(), attribute.String("container.id", "from-env"))
+}
+
+func TestKubernetesDetector(t *testing.T) {
+	dir, err := ioutil.TempDir("", "detectors")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for k, v := range map[string]string{
+		"KUBERNETES_SERVICE_HOST": "10.0.0.1",
+		"K8S_CLUSTER_NAME":        "prod",
+		"K8S_POD_NAME":            "api-7d9f",
+	} {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	d := &kubernetesDetector{
+		namespaceFile: writeFile(t, dir, "namespace", "granted\n"),
+		cgroupFile:    writeFile(t, dir, "cgroup", "12:pids:/docker/"+testContainerID+"\n"),
+	}
+	r, err := d.Detect(context.Background())
+	require.NoError(t, err)
+	attrs := r.Attributes()
+	assert.Contains(t, attrs, attribute.String("k8s.cluster.name", "prod"))
+	assert.Contains(t, attrs, attribute.String("k8s.namespace.name", "granted"))
+	assert.Contains(t, attrs, attribute.String("k8s.pod.name", "api-7d9f"))
+	assert.Contains(t, attrs, attribute.String("container.id", testContainerID))
+}
+
+type eksDetector struct {
+	attrs []attribute.KeyValue
+	err   error
+}
+
+func (d eksDetector) Detect(context.Context) (*resource.Resource, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	return resource.NewSchemaless(d.attrs...), nil
+}
+
+func TestKubernetesDetectorEKS(t *testing.T) {
+	os.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	defer os.Unsetenv("KUBERNETES_SERVICE_HOST")
+	os.Setenv("K8S_CLUSTER_NAME", "fallback")
+	defer os.Unsetenv("K8S_CLUSTER_NAME")
+
+	d := &kubernetesDetector{eks: eksDetector{attrs: []attribute.KeyValue{
+		attribute.String("cloud.platform", "aws_eks"),
+		attribute.String("k8s.cluster.name", "prod"),
+	}}}
+	r, err := d.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, r.Attributes(), attribute.String("cloud.platform", "aws_eks"))
+	assert.Contains(t, r.Attributes(), attribute.String("k8s.cluster.name", "prod"))
+
+	d.eks = eksDetector{err: errors.New("configmaps \"aws-auth\" is forbidden")}
+	r, err = d.Detect(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, r.Attributes(), attribute.String("k8s.cluster.name", "fallback"))
+}
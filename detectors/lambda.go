@@ -0,0 +1,42 @@
+package detectors
+
+import (
+	"context"
+	"os"
+	"strconv"
+
+	semconv "go.opentelemetry.io/collector/model/semconv/v1.5.0"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+type lambdaDetector struct{}
+
+// Lambda returns a detector which populates the cloud and faas attributes
+// when running in AWS Lambda, using the environment variables set by the
+// Lambda runtime.
+func Lambda() resource.Detector {
+	return lambdaDetector{}
+}
+
+func (lambdaDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	name := os.Getenv("AWS_LAMBDA_FUNCTION_NAME")
+	if name == "" {
+		return resource.Empty(), nil
+	}
+
+	attrs := appendNonEmpty([]attribute.KeyValue{
+		attribute.String(semconv.AttributeCloudProvider, semconv.AttributeCloudProviderAWS),
+		attribute.String(semconv.AttributeCloudPlatform, semconv.AttributeCloudPlatformAWSLambda),
+	},
+		attribute.String(semconv.AttributeCloudRegion, os.Getenv("AWS_REGION")),
+		attribute.String(semconv.AttributeFaaSName, name),
+		attribute.String(semconv.AttributeFaaSVersion, os.Getenv("AWS_LAMBDA_FUNCTION_VERSION")),
+		attribute.String(semconv.AttributeFaaSInstance, os.Getenv("AWS_LAMBDA_LOG_STREAM_NAME")),
+	)
+	// The memory size is reported in MB, whereas faas.max_memory is in bytes.
+	if mb, err := strconv.Atoi(os.Getenv("AWS_LAMBDA_FUNCTION_MEMORY_SIZE")); err == nil {
+		attrs = append(attrs, attribute.Int(semconv.AttributeFaaSMaxMemory, mb*1024*1024))
+	}
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
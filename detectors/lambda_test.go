@@ -0,0 +1,36 @@
+package detectors
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestLambdaDetector(t *testing.T) {
+	r, err := Lambda().Detect(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, r.Attributes())
+
+	for k, v := range map[string]string{
+		"AWS_LAMBDA_FUNCTION_NAME":        "granted-approvals",
+		"AWS_LAMBDA_FUNCTION_VERSION":     "$LATEST",
+		"AWS_LAMBDA_FUNCTION_MEMORY_SIZE": "128",
+		"AWS_REGION":                      "ap-southeast-2",
+	} {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	r, err = Lambda().Detect(context.Background())
+	require.NoError(t, err)
+	attrs := r.Attributes()
+	assert.Contains(t, attrs, attribute.String("cloud.platform", "aws_lambda"))
+	assert.Contains(t, attrs, attribute.String("cloud.region", "ap-southeast-2"))
+	assert.Contains(t, attrs, attribute.String("faas.name", "granted-approvals"))
+	assert.Contains(t, attrs, attribute.String("faas.version", "$LATEST"))
+	assert.Contains(t, attrs, attribute.Int("faas.max_memory", 128*1024*1024))
+}
@@ -4,8 +4,11 @@ go 1.16
 
 require (
 	github.com/StackExchange/wmi v1.2.1 // indirect
+	github.com/aws/aws-sdk-go-v2 v1.11.0
+	github.com/aws/smithy-go v1.9.0
 	github.com/felixge/httpsnoop v1.0.2
 	github.com/go-chi/chi/v5 v5.0.7
+	github.com/go-redis/redis/v8 v8.11.4
 	github.com/golang/protobuf v1.5.2
 	github.com/sethvargo/go-envconfig v0.4.0
 	github.com/shirou/gopsutil v3.21.10+incompatible // indirect
@@ -13,10 +16,14 @@ require (
 	github.com/tklauser/go-sysconf v0.3.9 // indirect
 	go.opentelemetry.io/collector/model v0.40.0
 	go.opentelemetry.io/contrib v0.23.0
-	go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc v0.28.0
+	go.opentelemetry.io/contrib/detectors/aws/ec2 v1.3.0
+	go.opentelemetry.io/contrib/detectors/aws/eks v1.3.0
+	go.opentelemetry.io/contrib/detectors/gcp v1.3.0
 	go.opentelemetry.io/contrib/instrumentation/host v0.23.0
 	go.opentelemetry.io/contrib/instrumentation/runtime v0.23.0
+	go.opentelemetry.io/contrib/propagators/aws v1.3.0
 	go.opentelemetry.io/contrib/propagators/b3 v1.3.0
+	go.opentelemetry.io/contrib/propagators/jaeger v1.3.0
 	go.opentelemetry.io/contrib/propagators/ot v1.2.0
 	go.opentelemetry.io/otel v1.3.0
 	go.opentelemetry.io/otel/exporters/otlp/otlpmetric v0.26.0
@@ -25,8 +32,14 @@ require (
 	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc v1.2.0
 	go.opentelemetry.io/otel/metric v0.26.0
 	go.opentelemetry.io/otel/sdk v1.3.0
+	go.opentelemetry.io/otel/sdk/export/metric v0.26.0
 	go.opentelemetry.io/otel/sdk/metric v0.26.0
 	go.opentelemetry.io/otel/trace v1.3.0
+	go.opentelemetry.io/proto/otlp v0.11.0
+	go.uber.org/goleak v1.1.11-0.20210813005559-691160354723
+	go.uber.org/multierr v1.6.0
 	go.uber.org/zap v1.19.1
+	golang.org/x/net v0.0.0-20210614182718-04defd469f4e
 	google.golang.org/grpc v1.42.0
+	google.golang.org/protobuf v1.27.1
 )
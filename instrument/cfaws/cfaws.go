@@ -0,0 +1,109 @@
+// Package cfaws instruments the AWS SDK for Go v2, so that calls to AWS
+// services are recorded as client spans.
+package cfaws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/common-fate/observability/instrument/cfaws"
+
+// Attributes recorded on AWS client spans, in addition to rpc.system,
+// rpc.service and rpc.method.
+const (
+	RegionKey    = attribute.Key("aws.region")
+	RequestIDKey = attribute.Key("aws.request_id")
+)
+
+// config is used to configure the instrumentation.
+type config struct {
+	TracerProvider oteltrace.TracerProvider
+}
+
+// Option specifies instrumentation configuration options.
+type Option interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// WithTracerProvider specifies a tracer provider to use for creating a
+// tracer. If none is specified, the global provider configured by the
+// launcher is used.
+func WithTracerProvider(provider oteltrace.TracerProvider) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.TracerProvider = provider
+	})
+}
+
+// Instrument adds middleware to cfg which records each API call made by
+// the clients created from it as a client span named after the service
+// and operation, such as "DynamoDB.GetItem".
+//
+//	cfg, err := config.LoadDefaultConfig(ctx)
+//	cfaws.Instrument(&cfg)
+//	db := dynamodb.NewFromConfig(cfg)
+func Instrument(cfg *aws.Config, opts ...Option) {
+	var c config
+	for _, opt := range opts {
+		opt.apply(&c)
+	}
+	cfg.APIOptions = append(cfg.APIOptions, func(stack *middleware.Stack) error {
+		// The middleware is added last, so that the service and operation
+		// have been registered in the context.
+		return stack.Initialize.Add(tracingMiddleware{config: c}, middleware.After)
+	})
+}
+
+type tracingMiddleware struct {
+	config config
+}
+
+func (tracingMiddleware) ID() string {
+	return "CFAWSTracing"
+}
+
+func (m tracingMiddleware) HandleInitialize(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+	tp := m.config.TracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	service, operation := awsmiddleware.GetServiceID(ctx), awsmiddleware.GetOperationName(ctx)
+	ctx, span := tp.Tracer(instrumentationName).Start(ctx, service+"."+operation,
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+		oteltrace.WithAttributes(
+			semconv.RPCSystemKey.String("aws-api"),
+			semconv.RPCServiceKey.String(service),
+			semconv.RPCMethodKey.String(operation),
+			RegionKey.String(awsmiddleware.GetRegion(ctx)),
+		),
+	)
+	defer span.End()
+
+	out, metadata, err := next.HandleInitialize(ctx, in)
+	if id, ok := awsmiddleware.GetRequestIDMetadata(metadata); ok {
+		span.SetAttributes(RequestIDKey.String(id))
+	}
+	if resp, ok := awsmiddleware.GetRawResponse(metadata).(*smithyhttp.Response); ok {
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return out, metadata, err
+}
@@ -0,0 +1,123 @@
+package cfaws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// stubClient invokes operations through a middleware stack built the way
+// the service clients generated by the AWS SDK build theirs, against an
+// HTTP endpoint.
+type stubClient struct {
+	cfg      aws.Config
+	endpoint string
+}
+
+func (c stubClient) invoke(ctx context.Context, operation string) error {
+	stack := middleware.NewStack(operation, smithyhttp.NewStackRequest)
+	err := stack.Initialize.Add(&awsmiddleware.RegisterServiceMetadata{
+		ServiceID:     "DynamoDB",
+		Region:        c.cfg.Region,
+		OperationName: operation,
+	}, middleware.Before)
+	if err != nil {
+		return err
+	}
+	err = stack.Serialize.Add(middleware.SerializeMiddlewareFunc("Serialize", func(ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler) (middleware.SerializeOutput, middleware.Metadata, error) {
+		req := in.Request.(*smithyhttp.Request)
+		req.Method = http.MethodPost
+		req.URL, _ = url.Parse(c.endpoint)
+		return next.HandleSerialize(ctx, in)
+	}), middleware.After)
+	if err != nil {
+		return err
+	}
+	err = stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("Deserialize", func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (middleware.DeserializeOutput, middleware.Metadata, error) {
+		out, metadata, err := next.HandleDeserialize(ctx, in)
+		if err != nil {
+			return out, metadata, err
+		}
+		resp := out.RawResponse.(*smithyhttp.Response)
+		awsmiddleware.SetRequestIDMetadata(&metadata, resp.Header.Get("X-Amzn-Requestid"))
+		if resp.StatusCode != http.StatusOK {
+			return out, metadata, &smithy.GenericAPIError{Code: resp.Header.Get("X-Amzn-Errortype"), Message: "request failed"}
+		}
+		return out, metadata, nil
+	}), middleware.After)
+	if err != nil {
+		return err
+	}
+	if err := awsmiddleware.AddRawResponseToMetadata(stack); err != nil {
+		return err
+	}
+	for _, fn := range c.cfg.APIOptions {
+		if err := fn(stack); err != nil {
+			return err
+		}
+	}
+	handler := middleware.DecorateHandler(smithyhttp.NewClientHandler(http.DefaultClient), stack)
+	_, _, err = handler.Handle(ctx, struct{}{})
+	return err
+}
+
+func TestInstrument(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Amzn-Requestid", "req-123")
+		if r.URL.Path == "/missing" {
+			w.Header().Set("X-Amzn-Errortype", "ResourceNotFoundException")
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	cfg := aws.Config{Region: "ap-southeast-2"}
+	Instrument(&cfg, WithTracerProvider(tp))
+
+	require.NoError(t, stubClient{cfg: cfg, endpoint: srv.URL}.invoke(context.Background(), "GetItem"))
+	err := stubClient{cfg: cfg, endpoint: srv.URL + "/missing"}.invoke(context.Background(), "PutItem")
+	require.Error(t, err)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "DynamoDB.GetItem", spans[0].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	assert.Subset(t, spans[0].Attributes(), []attribute.KeyValue{
+		attribute.String("rpc.system", "aws-api"),
+		attribute.String("rpc.service", "DynamoDB"),
+		attribute.String("rpc.method", "GetItem"),
+		attribute.String("aws.region", "ap-southeast-2"),
+		attribute.String("aws.request_id", "req-123"),
+		attribute.Int("http.status_code", http.StatusOK),
+	})
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	assert.Equal(t, "DynamoDB.PutItem", spans[1].Name())
+	assert.Subset(t, spans[1].Attributes(), []attribute.KeyValue{
+		attribute.String("rpc.method", "PutItem"),
+		attribute.String("aws.request_id", "req-123"),
+		attribute.Int("http.status_code", http.StatusBadRequest),
+	})
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, err.Error(), spans[1].Status().Description)
+	require.Len(t, spans[1].Events(), 1)
+	assert.Equal(t, "exception", spans[1].Events()[0].Name)
+}
@@ -0,0 +1,34 @@
+// Package cfchi instruments chi routers, naming server spans after the
+// matched route pattern rather than the request path.
+package cfchi
+
+import (
+	"net/http"
+
+	"github.com/common-fate/observability/instrument/cfhttp"
+	"github.com/go-chi/chi/v5"
+)
+
+// Middleware returns chi middleware which traces requests as
+// cfhttp.Middleware does, naming each server span after the full route
+// pattern matched by the router, such as "/api/v1/requests/{id}", so that
+// span names have low cardinality. Requests which match no route are
+// named "HTTP GET" and so on.
+//
+//	r := chi.NewRouter()
+//	r.Use(cfchi.Middleware())
+//	r.Get("/api/v1/requests/{id}", getRequest)
+func Middleware(opts ...cfhttp.Option) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return cfhttp.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+			// The route is only known once the router has matched the
+			// request, which happens further down the middleware chain.
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				if route := rctx.RoutePattern(); route != "" {
+					cfhttp.SetRoute(r.Context(), route)
+				}
+			}
+		}), opts...)
+	}
+}
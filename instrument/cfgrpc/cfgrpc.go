@@ -0,0 +1,170 @@
+// Package cfgrpc instruments gRPC servers and clients with spans and RPC
+// metrics, using the tracer provider, meter provider and propagators
+// configured by the launcher.
+//
+//	srv := grpc.NewServer(cfgrpc.ServerOptions()...)
+//	conn, err := grpc.Dial(target, append(cfgrpc.DialOptions(), grpc.WithInsecure())...)
+package cfgrpc
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/common-fate/observability/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/unit"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const instrumentationName = "github.com/common-fate/observability/instrument/cfgrpc"
+
+// ServerOptions returns the options which instrument a gRPC server.
+func ServerOptions(opts ...Option) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(UnaryServerInterceptor(opts...)),
+		grpc.ChainStreamInterceptor(StreamServerInterceptor(opts...)),
+	}
+}
+
+// DialOptions returns the options which instrument a gRPC client.
+func DialOptions(opts ...Option) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(UnaryClientInterceptor(opts...)),
+		grpc.WithChainStreamInterceptor(StreamClientInterceptor(opts...)),
+	}
+}
+
+// UnaryServerInterceptor traces unary RPCs and records their duration in
+// the rpc.server.duration histogram.
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	c := newConfig(opts)
+	trace := otelgrpc.UnaryServerInterceptor(c.tracingOptions()...)
+	duration := newDuration(c, "rpc.server.duration", "Duration of inbound RPCs")
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return trace(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			start := time.Now()
+			resp, err := handler(ctx, req)
+			duration.record(ctx, info.FullMethod, start, err)
+			return resp, err
+		})
+	}
+}
+
+// StreamServerInterceptor traces streaming RPCs and records their duration
+// in the rpc.server.duration histogram.
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	c := newConfig(opts)
+	trace := otelgrpc.StreamServerInterceptor(c.tracingOptions()...)
+	duration := newDuration(c, "rpc.server.duration", "Duration of inbound RPCs")
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return trace(srv, ss, info, func(srv interface{}, ss grpc.ServerStream) error {
+			start := time.Now()
+			err := handler(srv, ss)
+			duration.record(ss.Context(), info.FullMethod, start, err)
+			return err
+		})
+	}
+}
+
+// UnaryClientInterceptor traces unary RPCs, propagates the trace context
+// to the server, and records their duration in the rpc.client.duration
+// histogram.
+func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
+	c := newConfig(opts)
+	trace := otelgrpc.UnaryClientInterceptor(c.tracingOptions()...)
+	duration := newDuration(c, "rpc.client.duration", "Duration of outbound RPCs")
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		return trace(ctx, method, req, reply, cc, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, callOpts ...grpc.CallOption) error {
+			start := time.Now()
+			err := invoker(ctx, method, req, reply, cc, callOpts...)
+			duration.record(ctx, method, start, err)
+			return err
+		}, callOpts...)
+	}
+}
+
+// StreamClientInterceptor traces streaming RPCs, propagates the trace
+// context to the server, and records their duration in the
+// rpc.client.duration histogram. The duration of a stream is recorded when
+// it is finished by receiving an error or io.EOF.
+func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
+	c := newConfig(opts)
+	trace := otelgrpc.StreamClientInterceptor(c.tracingOptions()...)
+	duration := newDuration(c, "rpc.client.duration", "Duration of outbound RPCs")
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return trace(ctx, desc, cc, method, func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+			start := time.Now()
+			s, err := streamer(ctx, desc, cc, method, callOpts...)
+			if err != nil {
+				duration.record(ctx, method, start, err)
+				return s, err
+			}
+			return &clientStream{ClientStream: s, finish: func(err error) {
+				duration.record(ctx, method, start, err)
+			}}, nil
+		}, callOpts...)
+	}
+}
+
+// clientStream calls finish once the stream has ended.
+type clientStream struct {
+	grpc.ClientStream
+	finish func(error)
+	once   sync.Once
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.once.Do(func() {
+			if err == io.EOF {
+				s.finish(nil)
+				return
+			}
+			s.finish(err)
+		})
+	}
+	return err
+}
+
+// durationRecorder records the duration of RPCs.
+type durationRecorder struct {
+	histogram metric.Float64Histogram
+}
+
+func newDuration(c config, name, description string) durationRecorder {
+	return durationRecorder{
+		histogram: metric.Must(c.MeterProvider.Meter(instrumentationName)).NewFloat64Histogram(
+			name,
+			metric.WithDescription(description),
+			metric.WithUnit(unit.Milliseconds),
+		),
+	}
+}
+
+func (d durationRecorder) record(ctx context.Context, fullMethod string, start time.Time, err error) {
+	attrs := append(methodAttributes(fullMethod),
+		semconv.RPCSystemKey.String("grpc"),
+		otelgrpc.GRPCStatusCodeKey.Int64(int64(status.Code(err))),
+	)
+	d.histogram.Record(ctx, float64(time.Since(start))/float64(time.Millisecond), attrs...)
+}
+
+// methodAttributes returns the rpc.service and rpc.method attributes of a
+// gRPC method name, which has the form "/package.service/method".
+func methodAttributes(fullMethod string) []attribute.KeyValue {
+	parts := strings.SplitN(strings.TrimPrefix(fullMethod, "/"), "/", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+	return []attribute.KeyValue{
+		semconv.RPCServiceKey.String(parts[0]),
+		semconv.RPCMethodKey.String(parts[1]),
+	}
+}
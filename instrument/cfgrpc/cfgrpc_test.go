@@ -0,0 +1,62 @@
+package cfgrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/metrictest"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestInterceptors(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	mp := metrictest.NewMeterProvider()
+	opts := []Option{
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))),
+		WithMeterProvider(mp),
+		WithPropagators(propagation.TraceContext{}),
+	}
+
+	l := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(ServerOptions(opts...)...)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		append(DialOptions(opts...),
+			grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return l.Dial() }),
+			grpc.WithInsecure(),
+		)...,
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, spans[0].SpanContext().TraceID(), spans[1].SpanContext().TraceID())
+
+	measured := metrictest.AsStructs(mp.MeasurementBatches)
+	require.Len(t, measured, 2)
+	names := []string{measured[0].Name, measured[1].Name}
+	assert.ElementsMatch(t, []string{"rpc.server.duration", "rpc.client.duration"}, names)
+	assert.Equal(t, metrictest.LabelsToMap(
+		attribute.String("rpc.service", "grpc.health.v1.Health"),
+		attribute.String("rpc.method", "Check"),
+		attribute.String("rpc.system", "grpc"),
+		attribute.Int64("rpc.grpc.status_code", 0),
+	), measured[0].Labels)
+}
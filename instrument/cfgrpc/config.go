@@ -0,0 +1,77 @@
+package cfgrpc
+
+import (
+	"github.com/common-fate/observability/otelgrpc"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// config is used to configure the interceptors.
+type config struct {
+	TracerProvider oteltrace.TracerProvider
+	MeterProvider  metric.MeterProvider
+	Propagators    propagation.TextMapPropagator
+}
+
+// Option specifies instrumentation configuration options.
+type Option interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// WithTracerProvider specifies a tracer provider to use for creating a
+// tracer, such as launcher.Launcher.TracerProvider. If none is specified,
+// the global provider configured by the launcher is used.
+func WithTracerProvider(provider oteltrace.TracerProvider) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.TracerProvider = provider
+	})
+}
+
+// WithMeterProvider specifies a meter provider to use for recording
+// metrics. If none is specified, the global provider configured by the
+// launcher is used.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.MeterProvider = provider
+	})
+}
+
+// WithPropagators specifies the propagators used to extract and inject the
+// trace context in request metadata. If none are specified, the global
+// propagators configured by the launcher are used.
+func WithPropagators(propagators propagation.TextMapPropagator) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.Propagators = propagators
+	})
+}
+
+func newConfig(opts []Option) config {
+	var c config
+	for _, opt := range opts {
+		opt.apply(&c)
+	}
+	if c.MeterProvider == nil {
+		c.MeterProvider = global.GetMeterProvider()
+	}
+	return c
+}
+
+// tracingOptions returns the options of the tracing interceptors.
+func (c config) tracingOptions() []otelgrpc.Option {
+	var opts []otelgrpc.Option
+	if c.TracerProvider != nil {
+		opts = append(opts, otelgrpc.WithTracerProvider(c.TracerProvider))
+	}
+	if c.Propagators != nil {
+		opts = append(opts, otelgrpc.WithPropagators(c.Propagators))
+	}
+	return opts
+}
@@ -0,0 +1,73 @@
+package cfhttp
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// config is used to configure the middleware and transport.
+type config struct {
+	TracerProvider oteltrace.TracerProvider
+	MeterProvider  metric.MeterProvider
+	Propagators    propagation.TextMapPropagator
+}
+
+// Option specifies instrumentation configuration options.
+type Option interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// WithTracerProvider specifies a tracer provider to use for creating a
+// tracer, such as launcher.Launcher.TracerProvider. If none is specified,
+// the global provider configured by the launcher is used.
+func WithTracerProvider(provider oteltrace.TracerProvider) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.TracerProvider = provider
+	})
+}
+
+// WithPropagators specifies the propagators used to extract the trace
+// context from incoming requests and inject it into outgoing requests. If
+// none are specified, the global propagators configured by the launcher
+// are used. An explicit propagator allows different propagation policies
+// in one process, such as only W3C trace context at the public edge:
+//
+//	cfhttp.Middleware(h, cfhttp.WithPropagators(propagation.TraceContext{}))
+func WithPropagators(propagators propagation.TextMapPropagator) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.Propagators = propagators
+	})
+}
+
+// WithMeterProvider specifies a meter provider to use for recording
+// metrics. If none is specified, the global provider configured by the
+// launcher is used.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.MeterProvider = provider
+	})
+}
+
+func newConfig(opts []Option) config {
+	var c config
+	for _, opt := range opts {
+		opt.apply(&c)
+	}
+	return c
+}
+
+// propagators returns the configured propagators, or the global ones.
+func (c config) propagators() propagation.TextMapPropagator {
+	if c.Propagators != nil {
+		return c.Propagators
+	}
+	return otel.GetTextMapPropagator()
+}
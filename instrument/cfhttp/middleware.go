@@ -0,0 +1,87 @@
+// Package cfhttp instruments net/http servers and clients with the tracer
+// provider and propagators configured by the launcher.
+package cfhttp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/felixge/httpsnoop"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/common-fate/observability/instrument/cfhttp"
+
+// Middleware traces the requests handled by next, creating a server span
+// for each request which continues the trace propagated by the caller.
+// Spans are named after the route template set with Route, such as
+// "/users/{id}", or "HTTP GET" if the request has no route.
+//
+//	mux := http.NewServeMux()
+//	mux.Handle("/users/", cfhttp.Route("/users/{id}", users))
+//	http.ListenAndServe(":8080", cfhttp.Middleware(mux))
+func Middleware(next http.Handler, opts ...Option) http.Handler {
+	c := newConfig(opts)
+	return handler{next: next, config: c}
+}
+
+type handler struct {
+	next   http.Handler
+	config config
+}
+
+func (h handler) tracer() oteltrace.Tracer {
+	tp := h.config.TracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return tp.Tracer(instrumentationName)
+}
+
+// ServeHTTP implements http.Handler.
+func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := h.config.propagators().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	rt := &routeHolder{}
+	ctx = context.WithValue(ctx, routeKey{}, rt)
+	ctx, span := h.tracer().Start(ctx, "HTTP "+r.Method,
+		oteltrace.WithSpanKind(oteltrace.SpanKindServer),
+		oteltrace.WithAttributes(semconv.NetAttributesFromHTTPRequest("tcp", r)...),
+		oteltrace.WithAttributes(semconv.EndUserAttributesFromHTTPRequest(r)...),
+	)
+	defer span.End()
+
+	status := 0
+	w = httpsnoop.Wrap(w, httpsnoop.Hooks{
+		WriteHeader: func(next httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
+			return func(code int) {
+				if status == 0 {
+					status = code
+				}
+				next(code)
+			}
+		},
+		Write: func(next httpsnoop.WriteFunc) httpsnoop.WriteFunc {
+			return func(b []byte) (int, error) {
+				if status == 0 {
+					status = http.StatusOK
+				}
+				return next(b)
+			}
+		},
+	})
+	r = r.WithContext(ctx)
+	h.next.ServeHTTP(w, r)
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	span.SetAttributes(semconv.HTTPServerAttributesFromHTTPRequest("", rt.route, r)...)
+	span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(status)...)
+	span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(status))
+	if rt.route != "" {
+		span.SetName(rt.route)
+	}
+}
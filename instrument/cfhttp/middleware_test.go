@@ -0,0 +1,40 @@
+package cfhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestMiddleware(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+
+	mux := http.NewServeMux()
+	mux.Handle("/users/", Route("/users/{id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, oteltrace.SpanContextFromContext(r.Context()).IsValid())
+		w.WriteHeader(http.StatusInternalServerError)
+	})))
+	h := Middleware(mux, WithTracerProvider(tp))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/123", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "/users/{id}", spans[0].Name())
+	assert.Equal(t, oteltrace.SpanKindServer, spans[0].SpanKind())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Contains(t, spans[0].Attributes(), attribute.String("http.route", "/users/{id}"))
+	assert.Contains(t, spans[0].Attributes(), attribute.Int("http.status_code", 500))
+	assert.Equal(t, "HTTP GET", spans[1].Name())
+	assert.Contains(t, spans[1].Attributes(), attribute.Int("http.status_code", 404))
+}
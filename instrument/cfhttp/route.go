@@ -0,0 +1,35 @@
+package cfhttp
+
+import (
+	"context"
+	"net/http"
+)
+
+type routeKey struct{}
+
+// routeHolder is shared between Middleware and the handlers it wraps, so
+// that the route matched by a router further down the chain can name the
+// server span.
+type routeHolder struct {
+	route string
+}
+
+// Route records the route template of the requests handled by h, such as
+// "/users/{id}", which names their server span and sets the http.route
+// attribute. Templates should not contain identifiers, so that spans for
+// the same operation share a name.
+func Route(route string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetRoute(r.Context(), route)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// SetRoute records the route template of the request being handled with
+// ctx, for routers which match routes themselves. It has no effect if the
+// request is not handled by Middleware.
+func SetRoute(ctx context.Context, route string) {
+	if rt, ok := ctx.Value(routeKey{}).(*routeHolder); ok {
+		rt.route = route
+	}
+}
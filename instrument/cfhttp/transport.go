@@ -0,0 +1,126 @@
+package cfhttp
+
+import (
+	"net/http"
+	"net/http/httptrace"
+	"net/textproto"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/metric/unit"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Attributes recorded on the client spans of redirected requests.
+const (
+	// RedirectCountKey is the number of redirects which were followed to
+	// make the request.
+	RedirectCountKey = attribute.Key("http.redirect_count")
+	// RedirectedFromKey is the URL of the request which was redirected.
+	RedirectedFromKey = attribute.Key("http.redirected_from")
+)
+
+// Transport is an http.RoundTripper which traces outgoing requests,
+// propagates the trace context to the server, and records the duration of
+// each request in the http.client.duration histogram.
+type Transport struct {
+	base     http.RoundTripper
+	config   config
+	duration metric.Float64Histogram
+}
+
+var _ http.RoundTripper = &Transport{}
+
+// NewTransport wraps base, or http.DefaultTransport if base is nil.
+//
+//	client := &http.Client{Transport: cfhttp.NewTransport(nil)}
+func NewTransport(base http.RoundTripper, opts ...Option) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	c := newConfig(opts)
+	mp := c.MeterProvider
+	if mp == nil {
+		mp = global.GetMeterProvider()
+	}
+	return &Transport{
+		base:   base,
+		config: c,
+		duration: metric.Must(mp.Meter(instrumentationName)).NewFloat64Histogram(
+			"http.client.duration",
+			metric.WithDescription("Duration of outgoing HTTP requests"),
+			metric.WithUnit(unit.Milliseconds),
+		),
+	}
+}
+
+// RoundTrip implements http.RoundTripper. The client span ends when the
+// response headers are received.
+//
+// http.Client calls RoundTrip for each hop of a redirect chain, so each hop
+// has its own client span. Spans for redirected requests record the hop
+// number in RedirectCountKey and the URL which redirected them in
+// RedirectedFromKey. Informational responses, such as 103 Early Hints, are
+// recorded as span events.
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	tp := t.config.TracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	ctx, span := tp.Tracer(instrumentationName).Start(r.Context(), "HTTP "+r.Method,
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+		oteltrace.WithAttributes(semconv.HTTPClientAttributesFromHTTPRequest(r)...),
+		oteltrace.WithAttributes(redirectAttributes(r)...),
+	)
+	defer span.End()
+
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, _ textproto.MIMEHeader) error {
+			span.AddEvent("http.informational_response", oteltrace.WithAttributes(semconv.HTTPStatusCodeKey.Int(code)))
+			return nil
+		},
+	})
+	// The propagation headers are injected afresh for each request, as
+	// http.Client copies the headers of the original request, including
+	// the previous hop's trace context, when following redirects.
+	r = r.Clone(ctx)
+	t.config.propagators().Inject(ctx, propagation.HeaderCarrier(r.Header))
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(r)
+	attrs := []attribute.KeyValue{semconv.HTTPMethodKey.String(r.Method), semconv.NetPeerNameKey.String(r.URL.Hostname())}
+	if err != nil {
+		t.duration.Record(ctx, float64(time.Since(start))/float64(time.Millisecond), attrs...)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+	attrs = append(attrs, semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
+	t.duration.Record(ctx, float64(time.Since(start))/float64(time.Millisecond), attrs...)
+	span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(resp.StatusCode)...)
+	span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(resp.StatusCode))
+	return resp, nil
+}
+
+// redirectAttributes describes the redirect chain which led to r. The
+// client sets the Response of a redirected request to the response which
+// redirected it.
+func redirectAttributes(r *http.Request) []attribute.KeyValue {
+	if r.Response == nil || r.Response.Request == nil {
+		return nil
+	}
+	hops := 0
+	for prev := r; prev.Response != nil && prev.Response.Request != nil; prev = prev.Response.Request {
+		hops++
+	}
+	return []attribute.KeyValue{
+		RedirectCountKey.Int(hops),
+		RedirectedFromKey.String(r.Response.Request.URL.Redacted()),
+	}
+}
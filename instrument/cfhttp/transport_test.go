@@ -0,0 +1,53 @@
+package cfhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTransportRedirects(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+
+	var traceparents []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+		switch r.URL.Path {
+		case "/a":
+			http.Redirect(w, r, "/b", http.StatusFound)
+		case "/b":
+			http.Redirect(w, r, "/c", http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewTransport(nil, WithTracerProvider(tp))}
+	req, err := http.NewRequestWithContext(context.Background(), "GET", srv.URL+"/a", nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	spans := sr.Ended()
+	require.Len(t, spans, 3)
+	require.Len(t, traceparents, 3)
+	for i, s := range spans {
+		assert.Contains(t, traceparents[i], s.SpanContext().SpanID().String())
+	}
+	assert.NotContains(t, spans[0].Attributes(), RedirectCountKey.Int(0))
+	assert.Contains(t, spans[1].Attributes(), RedirectCountKey.Int(1))
+	assert.Contains(t, spans[1].Attributes(), RedirectedFromKey.String(srv.URL+"/a"))
+	assert.Contains(t, spans[2].Attributes(), RedirectCountKey.Int(2))
+	assert.Contains(t, spans[2].Attributes(), RedirectedFromKey.String(srv.URL+"/b"))
+}
@@ -0,0 +1,122 @@
+// Package cfredis instruments go-redis clients, so that cache operations
+// are recorded as client spans.
+package cfredis
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/common-fate/observability/instrument/cfredis"
+
+// PipelineLengthKey records the number of commands sent in a pipeline.
+const PipelineLengthKey = attribute.Key("db.redis.pipeline_length")
+
+// config is used to configure the instrumentation.
+type config struct {
+	TracerProvider oteltrace.TracerProvider
+}
+
+// Option specifies instrumentation configuration options.
+type Option interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// WithTracerProvider specifies a tracer provider to use for creating a
+// tracer. If none is specified, the global provider configured by the
+// launcher is used.
+func WithTracerProvider(provider oteltrace.TracerProvider) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.TracerProvider = provider
+	})
+}
+
+// Instrument adds a hook to client which records each command it runs as
+// a child span of the caller's span.
+//
+//	rdb := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+//	cfredis.Instrument(rdb)
+func Instrument(client redis.UniversalClient, opts ...Option) {
+	client.AddHook(NewHook(opts...))
+}
+
+// NewHook returns a hook which records each command run by the client it
+// is added to as a client span, named after the command, such as "get".
+// Pipelines are recorded as a single span named "pipeline". Command
+// arguments are not recorded, as they may contain sensitive values.
+func NewHook(opts ...Option) redis.Hook {
+	var c config
+	for _, opt := range opts {
+		opt.apply(&c)
+	}
+	return hook{config: c}
+}
+
+type hook struct {
+	config config
+}
+
+func (h hook) start(ctx context.Context, name string, attrs ...attribute.KeyValue) context.Context {
+	tp := h.config.TracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	attrs = append(attrs, semconv.DBSystemRedis)
+	ctx, _ = tp.Tracer(instrumentationName).Start(ctx, name,
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+		oteltrace.WithAttributes(attrs...),
+	)
+	return ctx
+}
+
+// end ends the span started in ctx, recording the first error of cmds. A
+// missing key is not an error.
+func end(ctx context.Context, cmds ...redis.Cmder) {
+	span := oteltrace.SpanFromContext(ctx)
+	for _, cmd := range cmds {
+		if err := cmd.Err(); err != nil && err != redis.Nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			break
+		}
+	}
+	span.End()
+}
+
+func (h hook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return h.start(ctx, cmd.FullName(), semconv.DBOperationKey.String(cmd.FullName())), nil
+}
+
+func (h hook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	end(ctx, cmd)
+	return nil
+}
+
+func (h hook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	names := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		names[i] = cmd.FullName()
+	}
+	return h.start(ctx, "pipeline",
+		semconv.DBOperationKey.String(strings.Join(names, " ")),
+		PipelineLengthKey.Int(len(cmds)),
+	), nil
+}
+
+func (h hook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	end(ctx, cmds...)
+	return nil
+}
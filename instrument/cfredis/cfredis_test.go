@@ -0,0 +1,87 @@
+package cfredis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestHook(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	h := NewHook(WithTracerProvider(tp))
+	ctx := context.Background()
+
+	get := redis.NewStringCmd(ctx, "get", "session")
+	spanCtx, err := h.BeforeProcess(ctx, get)
+	require.NoError(t, err)
+	require.NoError(t, h.AfterProcess(spanCtx, get))
+
+	// A missing key is not an error.
+	missing := redis.NewStringCmd(ctx, "get", "missing")
+	missing.SetErr(redis.Nil)
+	spanCtx, err = h.BeforeProcess(ctx, missing)
+	require.NoError(t, err)
+	require.NoError(t, h.AfterProcess(spanCtx, missing))
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "get", spans[0].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	assert.Subset(t, spans[0].Attributes(), []attribute.KeyValue{
+		attribute.String("db.system", "redis"),
+		attribute.String("db.operation", "get"),
+	})
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+	assert.Empty(t, spans[1].Events())
+}
+
+func TestInstrument(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	rdb := redis.NewClient(&redis.Options{
+		Dialer: func(context.Context, string, string) (net.Conn, error) {
+			return nil, errors.New("connection refused")
+		},
+		MaxRetries: -1,
+	})
+	defer rdb.Close()
+	Instrument(rdb, WithTracerProvider(tp))
+
+	ctx := context.Background()
+	require.Error(t, rdb.Set(ctx, "session", "token", 0).Err())
+	_, err := rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Incr(ctx, "requests")
+		p.Expire(ctx, "requests", 0)
+		return nil
+	})
+	require.Error(t, err)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "set", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "connection refused", spans[0].Status().Description)
+	require.Len(t, spans[0].Events(), 1)
+	assert.Equal(t, "exception", spans[0].Events()[0].Name)
+
+	assert.Equal(t, "pipeline", spans[1].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[1].SpanKind())
+	assert.Subset(t, spans[1].Attributes(), []attribute.KeyValue{
+		attribute.String("db.system", "redis"),
+		attribute.String("db.operation", "incr expire"),
+		attribute.Int("db.redis.pipeline_length", 2),
+	})
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
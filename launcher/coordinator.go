@@ -0,0 +1,181 @@
+package launcher
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+
+	semconv "go.opentelemetry.io/collector/model/semconv/v1.5.0"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestCoordinator allows several launchers to run in the same process, as
+// in an integration test binary which starts each service in-process.
+// Launchers configured WithTestCoordinator don't export telemetry or
+// replace the global providers. Instead each is given its own tracer
+// provider, namespaced by its resource, which records spans in memory.
+//
+// Services should obtain tracers from Launcher.TracerProvider rather than
+// the global provider, so that their spans are attributed to them. Spans
+// created with the global provider are recorded without a service name.
+// Metrics are not recorded in test mode, and only the most recent
+// MaxRecordedSpans spans are kept.
+type TestCoordinator struct {
+	recorder *spanRecorder
+	once     sync.Once
+}
+
+// MaxRecordedSpans is the number of ended spans a TestCoordinator keeps.
+// Older spans are discarded, so that a long running offline process doesn't
+// grow without bound.
+const MaxRecordedSpans = 10000
+
+// NewTestCoordinator returns a coordinator with no registered launchers.
+func NewTestCoordinator() *TestCoordinator {
+	return &TestCoordinator{recorder: &spanRecorder{}}
+}
+
+// WithTestCoordinator registers the launcher with tc, in place of
+// exporting telemetry.
+func WithTestCoordinator(tc *TestCoordinator) Option {
+	return func(c *Config) {
+		c.testCoordinator = tc
+	}
+}
+
+// WithOfflineMode configures whether the launcher runs offline, as in CI.
+// An offline launcher makes no network calls: it skips resource detection
+// and the ingest policy, and records spans in memory with a TestCoordinator
+// instead of exporting them, unless one is configured with
+// WithTestCoordinator or WithTestExporter. The recorded spans are available from
+// Launcher.TestCoordinator.
+//
+// Offline mode is enabled by default when the OTEL_OFFLINE_MODE environment
+// variable is true.
+func WithOfflineMode(enabled bool) Option {
+	return func(c *Config) {
+		c.OfflineMode = enabled
+	}
+}
+
+// offlineModeFromEnv returns the default for WithOfflineMode.
+func offlineModeFromEnv() bool {
+	offline, _ := strconv.ParseBool(os.Getenv("OTEL_OFFLINE_MODE"))
+	return offline
+}
+
+// applyOfflineMode replaces the network dependent configuration of an
+// offline launcher.
+func applyOfflineMode(c *Config) {
+	if !c.OfflineMode {
+		return
+	}
+	c.resourceDetectors = nil
+	c.ContainerDetection = false
+	if c.testCoordinator == nil && c.testExporter == nil {
+		c.testCoordinator = NewTestCoordinator()
+	}
+}
+
+// TestCoordinator returns the coordinator the launcher is registered with,
+// or nil if it exports telemetry.
+func (ls Launcher) TestCoordinator() *TestCoordinator {
+	return ls.config.testCoordinator
+}
+
+// Spans returns the ended spans of all registered launchers, in the order
+// they ended.
+func (tc *TestCoordinator) Spans() []sdktrace.ReadOnlySpan {
+	return tc.recorder.ended()
+}
+
+// ServiceSpans returns the ended spans of the launchers with the given
+// service name, in the order they ended.
+func (tc *TestCoordinator) ServiceSpans(serviceName string) []sdktrace.ReadOnlySpan {
+	var spans []sdktrace.ReadOnlySpan
+	for _, s := range tc.recorder.ended() {
+		if v, ok := s.Resource().Set().Value(semconv.AttributeServiceName); ok && v.AsString() == serviceName {
+			spans = append(spans, s)
+		}
+	}
+	return spans
+}
+
+// register creates the tracer provider for a launcher. The first launcher
+// to register also installs a global provider which records spans with no
+// resource, so instrumentation using the global provider is still visible.
+func (tc *TestCoordinator) register(c Config) *pipeline {
+	tc.once.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(tc.recorder)))
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	})
+
+	sampler := sdktrace.AlwaysSample()
+	if c.Sampler != nil {
+		sampler = c.Sampler
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithSpanProcessor(tc.recorder),
+		sdktrace.WithResource(c.Resource),
+	)
+	return &pipeline{
+		signal:         "traces",
+		shutdown:       func(context.Context) error { return nil },
+		flush:          tp.ForceFlush,
+		tracerProvider: tp,
+	}
+}
+
+// TracerProvider returns the tracer provider which the launcher's service
+// should use. Unless the launcher is registered with a TestCoordinator,
+// this is the global tracer provider.
+func (ls Launcher) TracerProvider() trace.TracerProvider {
+	for _, p := range ls.pipelines {
+		if p.tracerProvider != nil {
+			return p.tracerProvider
+		}
+	}
+	return otel.GetTracerProvider()
+}
+
+// spanRecorder records the last MaxRecordedSpans ended spans in memory, in
+// a ring buffer. It is used in place of the SDK's tracetest.SpanRecorder so
+// that the test package isn't linked into services.
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []sdktrace.ReadOnlySpan
+	// next is the index of the oldest span, which the next span replaces
+	// once the buffer is full.
+	next int
+}
+
+func (r *spanRecorder) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (r *spanRecorder) OnEnd(s sdktrace.ReadOnlySpan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.spans) < MaxRecordedSpans {
+		r.spans = append(r.spans, s)
+		return
+	}
+	r.spans[r.next] = s
+	r.next = (r.next + 1) % len(r.spans)
+}
+
+func (r *spanRecorder) Shutdown(context.Context) error { return nil }
+
+func (r *spanRecorder) ForceFlush(context.Context) error { return nil }
+
+// ended returns a copy of the spans recorded, oldest first.
+func (r *spanRecorder) ended() []sdktrace.ReadOnlySpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	spans := make([]sdktrace.ReadOnlySpan, 0, len(r.spans))
+	spans = append(spans, r.spans[r.next:]...)
+	return append(spans, r.spans[:r.next]...)
+}
@@ -0,0 +1,77 @@
+//go:build !noop
+// +build !noop
+
+package launcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestCoordinator(t *testing.T) {
+	tc := NewTestCoordinator()
+	api := ConfigureOpentelemetry(WithServiceName("api"), WithTestCoordinator(tc))
+	defer api.Shutdown()
+	worker := ConfigureOpentelemetry(WithServiceName("worker"), WithTestCoordinator(tc))
+	defer worker.Shutdown()
+
+	ctx, span := api.TracerProvider().Tracer("test").Start(context.Background(), "request")
+	_, child := worker.TracerProvider().Tracer("test").Start(ctx, "job")
+	child.End()
+	span.End()
+
+	assert.Len(t, tc.Spans(), 2)
+	apiSpans := tc.ServiceSpans("api")
+	require.Len(t, apiSpans, 1)
+	assert.Equal(t, "request", apiSpans[0].Name())
+	workerSpans := tc.ServiceSpans("worker")
+	require.Len(t, workerSpans, 1)
+	assert.Equal(t, apiSpans[0].SpanContext().TraceID(), workerSpans[0].SpanContext().TraceID())
+}
+
+func TestOfflineMode(t *testing.T) {
+	os.Setenv("CI", "true")
+	defer os.Unsetenv("CI")
+	c, err := newConfig(WithServiceName("api"))
+	require.NoError(t, err)
+	assert.False(t, c.OfflineMode, "offline mode must be opted into")
+
+	os.Setenv("OTEL_OFFLINE_MODE", "true")
+	defer os.Unsetenv("OTEL_OFFLINE_MODE")
+	c, err = newConfig(WithServiceName("api"), WithECSDetector())
+	require.NoError(t, err)
+	assert.True(t, c.OfflineMode)
+	assert.Empty(t, c.resourceDetectors)
+
+	ls := ConfigureOpentelemetry(WithServiceName("api"), WithOfflineMode(true))
+	defer ls.Shutdown()
+	require.NotNil(t, ls.TestCoordinator())
+	_, span := ls.TracerProvider().Tracer("test").Start(context.Background(), "request")
+	span.End()
+	assert.Len(t, ls.TestCoordinator().ServiceSpans("api"), 1)
+
+	c, err = newConfig(WithServiceName("api"), WithOfflineMode(false))
+	require.NoError(t, err)
+	assert.Nil(t, c.testCoordinator)
+}
+
+func TestSpanRecorderBounded(t *testing.T) {
+	tc := NewTestCoordinator()
+	ls := ConfigureOpentelemetry(WithServiceName("api"), WithTestCoordinator(tc))
+	defer ls.Shutdown()
+
+	tracer := ls.TracerProvider().Tracer("test")
+	for i := 0; i < MaxRecordedSpans+10; i++ {
+		_, span := tracer.Start(context.Background(), fmt.Sprintf("span %d", i))
+		span.End()
+	}
+	spans := tc.Spans()
+	require.Len(t, spans, MaxRecordedSpans)
+	assert.Equal(t, "span 10", spans[0].Name())
+	assert.Equal(t, fmt.Sprintf("span %d", MaxRecordedSpans+9), spans[len(spans)-1].Name())
+}
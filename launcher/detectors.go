@@ -0,0 +1,210 @@
+//go:build !noop
+// +build !noop
+
+package launcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/common-fate/observability/detectors"
+	semconv "go.opentelemetry.io/collector/model/semconv/v1.5.0"
+	"go.opentelemetry.io/contrib/detectors/aws/ec2"
+	"go.opentelemetry.io/contrib/detectors/gcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// WithECSDetector enables detection of the ECS container, task and cluster
+// from the ECS task metadata endpoint. It has no effect outside ECS.
+func WithECSDetector() Option {
+	return func(c *Config) {
+		c.resourceDetectors = append(c.resourceDetectors, detectors.ECS())
+	}
+}
+
+// WithContainerDetector enables detection of the ID of the container the
+// process is running in, so that telemetry can be joined with container
+// logs. It has no effect outside a container. Container detection is
+// enabled by default, unless OTEL_CONTAINER_DETECTION_ENABLED is false.
+func WithContainerDetector() Option {
+	return func(c *Config) {
+		c.ContainerDetection = true
+	}
+}
+
+// WithoutContainerDetector disables detection of the container ID.
+func WithoutContainerDetector() Option {
+	return func(c *Config) {
+		c.ContainerDetection = false
+	}
+}
+
+// WithEC2Detector enables detection of the EC2 instance ID, type, image,
+// region and availability zone from the EC2 instance metadata service.
+// Outside EC2 the detector has no effect, but probing the metadata service
+// may delay startup briefly.
+func WithEC2Detector() Option {
+	return func(c *Config) {
+		c.resourceDetectors = append(c.resourceDetectors, ec2.NewResourceDetector())
+	}
+}
+
+// WithKubernetesDetector enables detection of the Kubernetes cluster,
+// namespace, pod and container, including on EKS. See detectors.Kubernetes
+// for the downward API environment variables it reads.
+// It has no effect outside Kubernetes.
+func WithKubernetesDetector() Option {
+	return func(c *Config) {
+		c.resourceDetectors = append(c.resourceDetectors, detectors.Kubernetes())
+	}
+}
+
+// WithGCPDetector enables detection of the GCP project, region, zone and
+// instance from the GCE metadata server, along with the cluster, namespace
+// and pod on GKE, or the service and revision on Cloud Run. Outside GCP the
+// detector has no effect, but probing the metadata server may delay
+// startup briefly.
+func WithGCPDetector() Option {
+	return func(c *Config) {
+		c.resourceDetectors = append(c.resourceDetectors, gcpDetector{})
+	}
+}
+
+// gcpDetector selects the GCP detector for the platform. Cloud Run also
+// provides the GCE metadata server, so the two are distinguished by the
+// environment variables set by Cloud Run.
+type gcpDetector struct{}
+
+func (gcpDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	if os.Getenv("K_SERVICE") != "" {
+		return gcp.NewCloudRun().Detect(ctx)
+	}
+	return (&gcp.GKE{}).Detect(ctx)
+}
+
+// WithAzureDetector enables detection of the Azure region, subscription
+// and VM from the Azure instance metadata service, on VMs and AKS nodes.
+// Outside Azure the detector has no effect, but probing the metadata
+// service may delay startup briefly.
+func WithAzureDetector() Option {
+	return func(c *Config) {
+		c.resourceDetectors = append(c.resourceDetectors, detectors.Azure())
+	}
+}
+
+// WithLambda configures the launcher for AWS Lambda. It enables detection
+// of the function name, version, region and instance, and exports spans
+// synchronously as they end rather than in batches, since the execution
+// environment may be frozen between invocations. Metrics are still
+// exported periodically, so handlers should call Launcher.Flush before
+// returning.
+func WithLambda() Option {
+	return func(c *Config) {
+		c.Lambda = true
+		c.resourceDetectors = append(c.resourceDetectors, detectors.Lambda())
+	}
+}
+
+// WithProcessResource enables detection of the process ID, executable name
+// and Go runtime. The command line and owner of the process are not
+// included, as they may contain secrets.
+func WithProcessResource() Option {
+	return func(c *Config) {
+		c.resourceDetectors = append(c.resourceDetectors, sdkDetector{
+			resource.WithProcessPID(),
+			resource.WithProcessExecutableName(),
+			resource.WithProcessRuntimeName(),
+			resource.WithProcessRuntimeVersion(),
+			resource.WithProcessRuntimeDescription(),
+		})
+	}
+}
+
+// WithOSResource enables detection of the operating system type and
+// description, such as "linux" and "Alpine Linux 3.15.0 (Linux 5.10.0 x86_64)",
+// along with the host architecture.
+func WithOSResource() Option {
+	return func(c *Config) {
+		c.resourceDetectors = append(c.resourceDetectors, sdkDetector{
+			resource.WithOSType(),
+			resource.WithOSDescription(),
+			resource.WithAttributes(attribute.String(semconv.AttributeHostArch, hostArch())),
+		})
+	}
+}
+
+// hostArch returns the host.arch value for the architecture the binary was
+// built for.
+func hostArch() string {
+	switch runtime.GOARCH {
+	case "arm":
+		return semconv.AttributeHostArchARM32
+	case "386":
+		return semconv.AttributeHostArchX86
+	case "ppc64le":
+		return semconv.AttributeHostArchPPC64
+	default:
+		return runtime.GOARCH
+	}
+}
+
+// sdkDetector adapts the detectors built into the SDK, which are only
+// available as resource options.
+type sdkDetector []resource.Option
+
+func (d sdkDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	return resource.New(ctx, d...)
+}
+
+// WithResourceDetectors adds custom detectors, such as a lookup in an
+// internal asset inventory, whose resources are merged into the launcher's
+// resource. Detectors run in the order they are configured, and later
+// detectors take precedence. A detector which fails is skipped, unless it
+// returns a partial resource, and the failure is logged at warn.
+func WithResourceDetectors(ds ...resource.Detector) Option {
+	return func(c *Config) {
+		c.resourceDetectors = append(c.resourceDetectors, ds...)
+	}
+}
+
+// schemalessDetector drops the schema URL from detected resources. Detectors
+// built against other versions of the semantic conventions would otherwise
+// fail to merge with the launcher's resource. Errors are annotated with the
+// type of the detector.
+type schemalessDetector struct {
+	resource.Detector
+}
+
+func (d schemalessDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	r, err := d.Detector.Detect(ctx)
+	if err != nil {
+		err = fmt.Errorf("%T: %w", d.Detector, err)
+	}
+	if r == nil {
+		return nil, err
+	}
+	return resource.NewSchemaless(r.Attributes()...), err
+}
+
+// resourceDetectors returns the detectors run to build the launcher's
+// resource, wrapped in the resource cache if it is enabled.
+func resourceDetectors(c *Config) []resource.Detector {
+	ds := make([]resource.Detector, len(c.resourceDetectors))
+	for i, d := range c.resourceDetectors {
+		ds[i] = schemalessDetector{d}
+	}
+	if c.ResourceCachePath != "" && len(ds) > 0 {
+		ds = []resource.Detector{newCachedDetector(ds, c.ResourceCachePath, c.ResourceCacheTTL)}
+	}
+	// The container detector runs first, so that the container ID found
+	// by a platform detector, such as the ECS detector, takes precedence.
+	// It isn't cached, as the cache may be shared by containers on the
+	// same host.
+	if c.ContainerDetection {
+		ds = append([]resource.Detector{schemalessDetector{detectors.Container()}}, ds...)
+	}
+	return ds
+}
@@ -0,0 +1,110 @@
+//go:build noop
+// +build noop
+
+package launcher
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// When built with the noop tag resource detection doesn't run, so that
+// the cloud detectors and their SDKs aren't linked. The detector options
+// are kept so that callers build unchanged.
+
+// DefaultResourceCacheTTL is how long cached resource detection results
+// are used when WithResourceCache is given no TTL.
+const DefaultResourceCacheTTL = time.Hour
+
+// WithECSDetector enables detection of the ECS container, task and cluster.
+// It has no effect when built with the noop tag.
+func WithECSDetector() Option {
+	return func(c *Config) {}
+}
+
+// WithContainerDetector enables detection of the ID of the container the
+// process is running in. It has no effect when built with the noop tag.
+func WithContainerDetector() Option {
+	return func(c *Config) {
+		c.ContainerDetection = true
+	}
+}
+
+// WithoutContainerDetector disables detection of the container ID.
+func WithoutContainerDetector() Option {
+	return func(c *Config) {
+		c.ContainerDetection = false
+	}
+}
+
+// WithEC2Detector enables detection of the EC2 instance. It has no effect
+// when built with the noop tag.
+func WithEC2Detector() Option {
+	return func(c *Config) {}
+}
+
+// WithKubernetesDetector enables detection of the Kubernetes cluster,
+// namespace, pod and container. It has no effect when built with the noop
+// tag.
+func WithKubernetesDetector() Option {
+	return func(c *Config) {}
+}
+
+// WithGCPDetector enables detection of the GCP project, region and
+// instance. It has no effect when built with the noop tag.
+func WithGCPDetector() Option {
+	return func(c *Config) {}
+}
+
+// WithAzureDetector enables detection of the Azure region, subscription
+// and VM. It has no effect when built with the noop tag.
+func WithAzureDetector() Option {
+	return func(c *Config) {}
+}
+
+// WithLambda configures the launcher for AWS Lambda. Resource detection
+// is skipped when built with the noop tag.
+func WithLambda() Option {
+	return func(c *Config) {
+		c.Lambda = true
+	}
+}
+
+// WithProcessResource enables detection of the process ID, executable name
+// and Go runtime. It has no effect when built with the noop tag.
+func WithProcessResource() Option {
+	return func(c *Config) {}
+}
+
+// WithOSResource enables detection of the operating system type and
+// description. It has no effect when built with the noop tag.
+func WithOSResource() Option {
+	return func(c *Config) {}
+}
+
+// WithResourceDetectors adds custom detectors, whose resources are merged
+// into the launcher's resource. They are not run when built with the noop
+// tag.
+func WithResourceDetectors(ds ...resource.Detector) Option {
+	return func(c *Config) {}
+}
+
+// WithResourceCache caches the results of resource detection in the file
+// at path. It has no effect when built with the noop tag.
+func WithResourceCache(path string, ttl time.Duration) Option {
+	return func(c *Config) {
+		c.ResourceCachePath = path
+		c.ResourceCacheTTL = ttl
+	}
+}
+
+// InvalidateResourceCache removes the resource cache at path. It does
+// nothing when built with the noop tag.
+func InvalidateResourceCache(path string) error {
+	return nil
+}
+
+func resourceDetectors(c *Config) []resource.Detector {
+	return nil
+}
@@ -0,0 +1,61 @@
+//go:build !noop
+// +build !noop
+
+package launcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+func TestWithProcessResource(t *testing.T) {
+	c, err := newConfig(WithServiceName("api"), WithProcessResource())
+	require.NoError(t, err)
+	attrs := c.Resource.Attributes()
+	assert.Contains(t, attrs, attribute.Int("process.pid", os.Getpid()))
+	assert.Contains(t, attrs, attribute.String("process.runtime.version", runtime.Version()))
+	assert.Contains(t, attrs, attribute.String("service.name", "api"))
+}
+
+func TestWithOSResource(t *testing.T) {
+	c, err := newConfig(WithServiceName("api"), WithOSResource())
+	require.NoError(t, err)
+	attrs := c.Resource.Attributes()
+	assert.Contains(t, attrs, attribute.String("os.type", runtime.GOOS))
+	assert.Contains(t, attrs, attribute.String("host.arch", hostArch()))
+}
+
+type testDetector struct {
+	attrs []attribute.KeyValue
+	err   error
+}
+
+func (d testDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	return resource.NewWithAttributes("https://opentelemetry.io/schemas/1.7.0", d.attrs...), d.err
+}
+
+func TestWithResourceDetectors(t *testing.T) {
+	c, err := newConfig(
+		WithServiceName("api"),
+		WithResourceDetectors(
+			testDetector{attrs: []attribute.KeyValue{attribute.String("asset.id", "a-123")}},
+			testDetector{attrs: []attribute.KeyValue{attribute.String("asset.owner", "platform")}, err: errors.New("inventory unavailable")},
+			testDetector{attrs: []attribute.KeyValue{attribute.String("asset.tier", "1")}, err: fmt.Errorf("%w: tier unknown", resource.ErrPartialResource)},
+		),
+	)
+	require.NoError(t, err)
+	attrs := c.Resource.Attributes()
+	assert.Contains(t, attrs, attribute.String("asset.id", "a-123"))
+	assert.NotContains(t, attrs, attribute.String("asset.owner", "platform"))
+	assert.Contains(t, attrs, attribute.String("asset.tier", "1"))
+	assert.Contains(t, attrs, attribute.String("service.name", "api"))
+}
@@ -0,0 +1,28 @@
+package launcher
+
+// SpanMatcher matches spans by name and attributes. Name and the attribute
+// values are patterns in the syntax of path.Match. A span matches if its
+// name matches Name, unless Name is empty, and it has every attribute in
+// Attributes with a matching value. Values which are not strings are
+// matched in their string form, such as "200" for an integer.
+type SpanMatcher struct {
+	Name       string
+	Attributes map[string]string
+}
+
+// WithDroppedSpans discards spans matching any of the matchers when they
+// end, instead of exporting them. For example, health checks can be
+// dropped with
+//
+//	launcher.WithDroppedSpans(
+//		launcher.SpanMatcher{Name: "GET /healthz"},
+//		launcher.SpanMatcher{Attributes: map[string]string{"http.user_agent": "ELB-HealthChecker/*"}},
+//	)
+//
+// Spans which are dropped are not counted by span metrics or passed to span
+// end hooks. Their child spans are still exported.
+func WithDroppedSpans(matchers ...SpanMatcher) Option {
+	return func(c *Config) {
+		c.DroppedSpans = append(c.DroppedSpans, matchers...)
+	}
+}
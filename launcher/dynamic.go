@@ -0,0 +1,49 @@
+package launcher
+
+import (
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DynamicResourceProvider provides resource attributes which can change
+// while the process runs, such as the active deployment color or a canary
+// flag. ResourceAttributes is called as each span ends, so it should be
+// cheap; see DynamicResource.
+type DynamicResourceProvider interface {
+	ResourceAttributes() []attribute.KeyValue
+}
+
+// DynamicResource is a DynamicResourceProvider whose attributes are set by
+// the application, for example when a deployment is promoted.
+type DynamicResource struct {
+	attrs atomic.Value
+}
+
+// NewDynamicResource returns a DynamicResource with the given attributes.
+func NewDynamicResource(attrs ...attribute.KeyValue) *DynamicResource {
+	d := &DynamicResource{}
+	d.Set(attrs...)
+	return d
+}
+
+// Set replaces the attributes. Spans which end afterwards are exported with
+// the new attributes.
+func (d *DynamicResource) Set(attrs ...attribute.KeyValue) {
+	d.attrs.Store(append([]attribute.KeyValue(nil), attrs...))
+}
+
+// ResourceAttributes returns the current attributes.
+func (d *DynamicResource) ResourceAttributes() []attribute.KeyValue {
+	attrs, _ := d.attrs.Load().([]attribute.KeyValue)
+	return attrs
+}
+
+// WithDynamicResource merges the attributes of p into the resource of each
+// span as it ends, in addition to the static resource. Metrics are exported
+// with the static resource only.
+func WithDynamicResource(p DynamicResourceProvider) Option {
+	return func(c *Config) {
+		c.DynamicResource = p
+	}
+}
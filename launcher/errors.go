@@ -0,0 +1,228 @@
+package launcher
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorLevel is the severity at which an OpenTelemetry error is reported.
+type ErrorLevel int
+
+const (
+	// ErrorTransient errors are expected to resolve themselves, such as a
+	// collector which is briefly unavailable. They are logged at debug.
+	ErrorTransient ErrorLevel = iota
+	// ErrorDegraded errors mean telemetry is being lost, such as rejected
+	// credentials or a collector which has been unavailable for some time.
+	// They are logged at warn.
+	ErrorDegraded
+	// ErrorFatal errors mean telemetry has been lost for a long period.
+	// They are logged at error, but never terminate the process.
+	ErrorFatal
+)
+
+func (l ErrorLevel) String() string {
+	switch l {
+	case ErrorTransient:
+		return "transient"
+	case ErrorDegraded:
+		return "degraded"
+	case ErrorFatal:
+		return "fatal"
+	default:
+		return "unknown"
+	}
+}
+
+// EscalationPolicy controls how the level of persistent errors increases.
+// Errors are grouped by their gRPC status code, and a group escalates
+// while errors keep occurring without a gap longer than ResetAfter.
+type EscalationPolicy struct {
+	// DegradedAfter is how long transient errors persist before they are
+	// reported as degraded. Zero disables escalation to degraded.
+	DegradedAfter time.Duration
+	// FatalAfter is how long errors persist before they are reported as
+	// fatal. Zero disables escalation to fatal.
+	FatalAfter time.Duration
+	// ResetAfter is how long a group must be free of errors before its
+	// escalation is reset.
+	ResetAfter time.Duration
+}
+
+// DefaultEscalationPolicy reports an exporter which has been failing for
+// five minutes at warn, and at error after thirty minutes.
+var DefaultEscalationPolicy = EscalationPolicy{
+	DegradedAfter: 5 * time.Minute,
+	FatalAfter:    30 * time.Minute,
+	ResetAfter:    2 * time.Minute,
+}
+
+// WithErrorEscalation configures the escalation policy of the default
+// error handler. It has no effect when WithErrorHandler is used.
+func WithErrorEscalation(policy EscalationPolicy) Option {
+	return func(c *Config) {
+		c.errorEscalation = policy
+	}
+}
+
+// defaultHandler logs OpenTelemetry errors at a level determined by the
+// kind of error and how long errors of that kind have persisted.
+type defaultHandler struct {
+	logger zap.Logger
+	policy EscalationPolicy
+	now    func() time.Time
+
+	mu      sync.Mutex
+	streaks map[codes.Code]*errorStreak
+}
+
+type errorStreak struct {
+	first, last time.Time
+}
+
+func newDefaultHandler(logger zap.Logger, policy EscalationPolicy) *defaultHandler {
+	return &defaultHandler{
+		logger:  logger,
+		policy:  policy,
+		now:     time.Now,
+		streaks: make(map[codes.Code]*errorStreak),
+	}
+}
+
+func (h *defaultHandler) Handle(err error) {
+	level := h.level(err)
+	switch level {
+	case ErrorTransient:
+		h.logger.Debug("opentelemetry error", zap.Error(err))
+	case ErrorDegraded:
+		h.logger.Warn("opentelemetry error", zap.Error(err), zap.Stringer("level", level))
+	default:
+		h.logger.Error("opentelemetry error", zap.Error(err), zap.Stringer("level", level))
+	}
+}
+
+// level returns the level at which err is reported, recording it in the
+// streak for its status code.
+func (h *defaultHandler) level(err error) ErrorLevel {
+	code := errorCode(err)
+	now := h.now()
+
+	h.mu.Lock()
+	s, ok := h.streaks[code]
+	if !ok || now.Sub(s.last) > h.policy.ResetAfter {
+		s = &errorStreak{first: now}
+		h.streaks[code] = s
+	}
+	s.last = now
+	elapsed := now.Sub(s.first)
+	h.mu.Unlock()
+
+	level := ErrorTransient
+	switch code {
+	case codes.Unauthenticated, codes.PermissionDenied, codes.InvalidArgument, codes.Unimplemented:
+		// These won't resolve without a configuration change.
+		level = ErrorDegraded
+	}
+	if h.policy.DegradedAfter > 0 && elapsed >= h.policy.DegradedAfter && level < ErrorDegraded {
+		level = ErrorDegraded
+	}
+	if h.policy.FatalAfter > 0 && elapsed >= h.policy.FatalAfter {
+		level = ErrorFatal
+	}
+	return level
+}
+
+// errorCode returns the gRPC status code of err, or codes.Unknown if err
+// does not carry a status.
+func errorCode(err error) codes.Code {
+	var se interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &se) {
+		return se.GRPCStatus().Code()
+	}
+	return codes.Unknown
+}
+
+// ErrorCategory distinguishes the kinds of error reported to the callback
+// registered with WithErrorCallback.
+type ErrorCategory int
+
+const (
+	// ErrorExport errors are failures to export or collect telemetry, such
+	// as an unavailable collector. They are usually transient.
+	ErrorExport ErrorCategory = iota
+	// ErrorDropped errors report telemetry which the pipelines dropped,
+	// such as batches evicted from the disk buffer.
+	ErrorDropped
+	// ErrorConfiguration errors won't resolve without a configuration
+	// change, such as rejected credentials or a pipeline which could not be
+	// started.
+	ErrorConfiguration
+)
+
+func (c ErrorCategory) String() string {
+	switch c {
+	case ErrorExport:
+		return "export"
+	case ErrorDropped:
+		return "dropped"
+	case ErrorConfiguration:
+		return "configuration"
+	default:
+		return "unknown"
+	}
+}
+
+// WithErrorCallback registers a callback which is called with every
+// OpenTelemetry error and its category, in addition to the error handler,
+// so that applications can page on configuration errors but only count
+// transient export failures. It is also called with setup errors which
+// are logged rather than returned, such as a failed startup probe under
+// FailOpen. It must not block.
+func WithErrorCallback(fn func(err error, category ErrorCategory)) Option {
+	return func(c *Config) {
+		c.errorCallback = fn
+	}
+}
+
+// callbackHandler passes each error and its category to a callback, and
+// then to the next handler.
+type callbackHandler struct {
+	next     otel.ErrorHandler
+	callback func(error, ErrorCategory)
+}
+
+func (h callbackHandler) Handle(err error) {
+	h.callback(err, errorCategory(err))
+	h.next.Handle(err)
+}
+
+// errorCategory returns the category of err.
+func errorCategory(err error) ErrorCategory {
+	var dropped interface{ Dropped() bool }
+	if errors.As(err, &dropped) && dropped.Dropped() {
+		return ErrorDropped
+	}
+	var se setupError
+	if errors.As(err, &se) {
+		return ErrorConfiguration
+	}
+	switch errorCode(err) {
+	case codes.Unauthenticated, codes.PermissionDenied, codes.InvalidArgument, codes.Unimplemented:
+		return ErrorConfiguration
+	}
+	return ErrorExport
+}
+
+// reportError passes err, which was logged rather than returned, to the
+// error callback.
+func (c Config) reportError(err error) {
+	if c.errorCallback != nil {
+		c.errorCallback(err, errorCategory(err))
+	}
+}
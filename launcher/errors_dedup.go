@@ -0,0 +1,182 @@
+package launcher
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+)
+
+// WithErrorDeduplication limits how often OpenTelemetry errors are passed
+// to the error handler, so that a collector outage doesn't flood the logs.
+// Each distinct error is reported the first time it occurs in an interval,
+// and at most maxPerInterval distinct errors are reported per interval;
+// zero means no limit. Errors which were suppressed are counted, and a
+// single SuppressedErrors summary is reported at the end of the interval.
+// At most 100 distinct errors are tracked per interval; any others are
+// only counted. It applies to both the default handler and
+// WithErrorHandler.
+func WithErrorDeduplication(interval time.Duration, maxPerInterval int) Option {
+	return func(c *Config) {
+		c.errorDedupInterval = interval
+		c.errorDedupLimit = maxPerInterval
+	}
+}
+
+const (
+	// maxTrackedErrors bounds the distinct errors tracked per interval.
+	maxTrackedErrors = 100
+
+	// maxSummarizedErrors is how many of the most frequent errors are
+	// listed in a SuppressedErrors summary.
+	maxSummarizedErrors = 5
+)
+
+// RepeatedError is an error which occurred more often than it was
+// reported during an interval.
+type RepeatedError struct {
+	Err error
+	// Count is the number of times Err occurred during the interval,
+	// including the time it was reported.
+	Count    int
+	Interval time.Duration
+}
+
+func (e RepeatedError) Error() string {
+	return fmt.Sprintf("%v (occurred %d times in %s)", e.Err, e.Count, e.Interval)
+}
+
+func (e RepeatedError) Unwrap() error {
+	return e.Err
+}
+
+// SuppressedErrors is reported by the error handler at the end of an
+// interval in which errors were suppressed.
+type SuppressedErrors struct {
+	// Total is the number of errors which were not reported during the
+	// interval.
+	Total int
+	// Top lists the most frequent suppressed errors, most frequent first.
+	Top      []RepeatedError
+	Interval time.Duration
+}
+
+func (e SuppressedErrors) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d errors suppressed in %s", e.Total, e.Interval)
+	for i, r := range e.Top {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		b.WriteString(r.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the most frequent suppressed error, so that the summary
+// is classified like it.
+func (e SuppressedErrors) Unwrap() error {
+	if len(e.Top) == 0 {
+		return nil
+	}
+	return e.Top[0]
+}
+
+// dedupHandler passes each distinct error message to the next handler at
+// most once per interval, and at most limit distinct messages per interval.
+// Suppressed errors are counted and summarized to the next handler when the
+// interval ends.
+type dedupHandler struct {
+	next     otel.ErrorHandler
+	interval time.Duration
+	limit    int
+
+	mu         sync.Mutex
+	open       bool
+	reported   int
+	suppressed int
+	seen       map[string]*dedupEntry
+}
+
+type dedupEntry struct {
+	err   error
+	count int
+	// suppressed is whether the error was suppressed at least once.
+	suppressed bool
+}
+
+func newDedupHandler(next otel.ErrorHandler, interval time.Duration, limit int) *dedupHandler {
+	return &dedupHandler{next: next, interval: interval, limit: limit}
+}
+
+func (h *dedupHandler) Handle(err error) {
+	msg := err.Error()
+
+	h.mu.Lock()
+	if !h.open {
+		h.open = true
+		h.reported = 0
+		h.suppressed = 0
+		h.seen = make(map[string]*dedupEntry)
+		time.AfterFunc(h.interval, h.flush)
+	}
+	e, ok := h.seen[msg]
+	switch {
+	case ok:
+		e.count++
+		e.suppressed = true
+		h.suppressed++
+		h.mu.Unlock()
+		return
+	case len(h.seen) >= maxTrackedErrors:
+		h.suppressed++
+		h.mu.Unlock()
+		return
+	}
+	e = &dedupEntry{err: err, count: 1}
+	h.seen[msg] = e
+	if h.limit > 0 && h.reported >= h.limit {
+		e.suppressed = true
+		h.suppressed++
+		h.mu.Unlock()
+		return
+	}
+	h.reported++
+	h.mu.Unlock()
+
+	h.next.Handle(err)
+}
+
+// flush ends the current interval, summarizing the errors which were
+// suppressed during it.
+func (h *dedupHandler) flush() {
+	h.mu.Lock()
+	summary := SuppressedErrors{Total: h.suppressed, Interval: h.interval}
+	for _, e := range h.seen {
+		if e.suppressed {
+			summary.Top = append(summary.Top, RepeatedError{Err: e.err, Count: e.count, Interval: h.interval})
+		}
+	}
+	h.open = false
+	h.seen = nil
+	h.mu.Unlock()
+
+	if summary.Total == 0 {
+		return
+	}
+	sort.Slice(summary.Top, func(i, j int) bool {
+		if summary.Top[i].Count != summary.Top[j].Count {
+			return summary.Top[i].Count > summary.Top[j].Count
+		}
+		return summary.Top[i].Err.Error() < summary.Top[j].Err.Error()
+	})
+	if len(summary.Top) > maxSummarizedErrors {
+		summary.Top = summary.Top[:maxSummarizedErrors]
+	}
+	h.next.Handle(summary)
+}
@@ -0,0 +1,123 @@
+package launcher
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDefaultHandlerEscalation(t *testing.T) {
+	now := time.Now()
+	h := newDefaultHandler(*zap.NewNop(), DefaultEscalationPolicy)
+	h.now = func() time.Time { return now }
+
+	unavailable := fmt.Errorf("exporting spans: %w", status.Error(codes.Unavailable, "connection refused"))
+	assert.Equal(t, ErrorTransient, h.level(unavailable))
+
+	for i := 0; i < 5; i++ {
+		now = now.Add(time.Minute)
+		h.level(unavailable)
+	}
+	assert.Equal(t, ErrorDegraded, h.level(unavailable))
+
+	for i := 0; i < 25; i++ {
+		now = now.Add(time.Minute)
+		h.level(unavailable)
+	}
+	assert.Equal(t, ErrorFatal, h.level(unavailable))
+
+	// A gap longer than ResetAfter starts a new streak.
+	now = now.Add(time.Hour)
+	assert.Equal(t, ErrorTransient, h.level(unavailable))
+
+	assert.Equal(t, ErrorDegraded, h.level(status.Error(codes.Unauthenticated, "invalid token")))
+	assert.Equal(t, ErrorTransient, h.level(errors.New("queue full")))
+}
+
+type recordingHandler struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (h *recordingHandler) Handle(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.errs = append(h.errs, err)
+}
+
+func (h *recordingHandler) handled() []error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]error(nil), h.errs...)
+}
+
+func TestDedupHandler(t *testing.T) {
+	next := &recordingHandler{}
+	h := newDedupHandler(next, 50*time.Millisecond, 2)
+
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+	for i := 0; i < 10; i++ {
+		h.Handle(unavailable)
+	}
+	h.Handle(errors.New("queue full"))
+	h.Handle(errors.New("context deadline exceeded"))
+	assert.Len(t, next.handled(), 2)
+
+	assert.Eventually(t, func() bool { return len(next.handled()) == 3 }, time.Second, 10*time.Millisecond)
+	errs := next.handled()
+	var summary SuppressedErrors
+	require.True(t, errors.As(errs[2], &summary))
+	assert.Equal(t, 10, summary.Total)
+	require.Len(t, summary.Top, 2)
+	assert.Equal(t, 10, summary.Top[0].Count)
+	assert.Equal(t, codes.Unavailable, errorCode(errs[2]))
+	assert.EqualError(t, summary.Top[1], "context deadline exceeded (occurred 1 times in 50ms)")
+
+	h.Handle(unavailable)
+	assert.Len(t, next.handled(), 4)
+}
+
+func TestDedupHandlerBounded(t *testing.T) {
+	next := &recordingHandler{}
+	h := newDedupHandler(next, 50*time.Millisecond, 1)
+
+	for i := 0; i < 3*maxTrackedErrors; i++ {
+		h.Handle(fmt.Errorf("error %d", i))
+		h.Handle(fmt.Errorf("error %d", i))
+	}
+	assert.Len(t, h.seen, maxTrackedErrors)
+
+	assert.Eventually(t, func() bool { return len(next.handled()) == 2 }, time.Second, 10*time.Millisecond)
+	var summary SuppressedErrors
+	require.True(t, errors.As(next.handled()[1], &summary))
+	assert.Equal(t, 6*maxTrackedErrors-1, summary.Total)
+	assert.Len(t, summary.Top, maxSummarizedErrors)
+	assert.Equal(t, "error 0", summary.Top[0].Err.Error())
+	assert.Equal(t, 2, summary.Top[0].Count)
+}
+
+type droppedError struct{ error }
+
+func (droppedError) Dropped() bool { return true }
+
+func TestErrorCallback(t *testing.T) {
+	var categories []ErrorCategory
+	c, err := newConfig(WithServiceName("api"), WithErrorCallback(func(err error, category ErrorCategory) {
+		categories = append(categories, category)
+	}))
+	require.NoError(t, err)
+
+	c.errorHandler.Handle(status.Error(codes.Unavailable, "connection refused"))
+	c.errorHandler.Handle(fmt.Errorf("exporting spans: %w", status.Error(codes.Unauthenticated, "invalid token")))
+	c.errorHandler.Handle(droppedError{errors.New("disk buffer exceeded 1024 bytes: dropped the 2 oldest batches")})
+	c.reportError(setupError{errors.New("invalid configuration: unsupported propagators")})
+	assert.Equal(t, []ErrorCategory{ErrorExport, ErrorConfiguration, ErrorDropped, ErrorConfiguration}, categories)
+}
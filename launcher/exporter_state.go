@@ -0,0 +1,51 @@
+package launcher
+
+import (
+	"sync"
+	"time"
+)
+
+// ExporterState describes the connectivity of an exporter's gRPC channel.
+type ExporterState struct {
+	// State is one of IDLE, CONNECTING, READY, TRANSIENT_FAILURE or SHUTDOWN.
+	State string
+	// Since is the time the channel entered State.
+	Since time.Time
+}
+
+// exporterStates records the latest state of each exporter channel,
+// keyed by signal ("traces" or "metrics").
+type exporterStates struct {
+	mu     sync.Mutex
+	states map[string]ExporterState
+}
+
+func newExporterStates() *exporterStates {
+	return &exporterStates{states: make(map[string]ExporterState)}
+}
+
+func (s *exporterStates) set(signal, state string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[signal] = ExporterState{State: state, Since: time.Now()}
+}
+
+func (s *exporterStates) snapshot() map[string]ExporterState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]ExporterState, len(s.states))
+	for k, v := range s.states {
+		out[k] = v
+	}
+	return out
+}
+
+// ExporterState returns the connectivity of each exporter channel, keyed by
+// signal ("traces" or "metrics"), so that health endpoints can report
+// whether telemetry is reaching the collector.
+func (ls Launcher) ExporterState() map[string]ExporterState {
+	if ls.config.exporterStates == nil {
+		return map[string]ExporterState{}
+	}
+	return ls.config.exporterStates.snapshot()
+}
@@ -0,0 +1,65 @@
+package launcher
+
+import (
+	"errors"
+	"time"
+)
+
+// FailureMode controls how ConfigureOpentelemetry treats errors starting
+// the export pipelines.
+type FailureMode int
+
+const (
+	// FailOpen logs errors starting the export pipelines, and continues
+	// without exporting telemetry. It is the default.
+	FailOpen FailureMode = iota
+	// FailClosed exits the process if the export pipelines can't be
+	// started, for services which must not run without telemetry.
+	FailClosed
+)
+
+// WithStartupProbe checks that the trace and metric endpoints are
+// reachable and accept the configured headers before the pipelines are
+// started, waiting up to timeout. A failure is logged with its likely
+// cause, such as a DNS, TLS or authentication error, and under FailClosed
+// it is treated as an error starting the pipelines.
+func WithStartupProbe(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.StartupProbeTimeout = timeout
+	}
+}
+
+// WithFailureMode configures how ConfigureOpentelemetry treats errors
+// starting the export pipelines. Configuration errors, such as a missing
+// service name, are always fatal.
+func WithFailureMode(mode FailureMode) Option {
+	return func(c *Config) {
+		c.FailureMode = mode
+	}
+}
+
+// setupError is returned by ConfigureOpentelemetryE when an export
+// pipeline can't be started.
+type setupError struct {
+	err error
+}
+
+func (e setupError) Error() string {
+	return "setup error: " + e.err.Error()
+}
+
+func (e setupError) Unwrap() error {
+	return e.err
+}
+
+// failOpen reports whether err, returned by ConfigureOpentelemetryE, is
+// ignored under the configured failure mode, logging it if so.
+func (ls Launcher) failOpen(err error) bool {
+	var se setupError
+	if ls.config.FailureMode != FailOpen || !errors.As(err, &se) {
+		return false
+	}
+	ls.config.logger.Sugar().Warnf("telemetry is disabled: %v", err)
+	ls.config.reportError(err)
+	return true
+}
@@ -0,0 +1,29 @@
+package launcher
+
+// ExportSummary describes a batch of telemetry which is about to leave the
+// process. It contains the attribute keys present, but never their values.
+type ExportSummary struct {
+	// Signal is "traces" or "metrics".
+	Signal string
+	// Destination is the endpoint the batch is sent to.
+	Destination string
+	// Count is the number of spans or metric records in the batch.
+	Count int
+	// AttributeKeys are the distinct attribute keys of the spans, including
+	// their events, or of the metric records, in sorted order.
+	AttributeKeys []string
+	// ResourceAttributeKeys are the distinct keys of the resources the
+	// batch is exported under, in sorted order.
+	ResourceAttributeKeys []string
+}
+
+// WithExportInspector calls inspect with a summary of every batch of spans
+// and metrics before it is exported, so that a compliance log can record
+// which categories of data leave the process and where they are sent.
+// Batches which are retried are reported for every attempt. inspect is
+// called on the export goroutine, so it should be fast.
+func WithExportInspector(inspect func(ExportSummary)) Option {
+	return func(c *Config) {
+		c.ExportInspector = inspect
+	}
+}
@@ -0,0 +1,138 @@
+package launcher
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsecureAllowedFor(t *testing.T) {
+	patterns := []string{"localhost", "*.internal"}
+	assert.True(t, insecureAllowed("localhost:4317", patterns))
+	assert.True(t, insecureAllowed("collector.internal:4317", patterns))
+	assert.True(t, insecureAllowed("Collector.Internal", patterns))
+	assert.False(t, insecureAllowed("ingest.commonfate.io:443", patterns))
+
+	c := Config{
+		ServiceName:                  "api",
+		SpanExporterEndpoint:         "ingest.commonfate.io:443",
+		SpanExporterEndpointInsecure: true,
+	}
+	assert.NoError(t, validateConfiguration(c))
+	WithInsecureAllowedFor(patterns...)(&c)
+	assert.Error(t, validateConfiguration(c))
+	c.SpanExporterEndpoint = "localhost:4317"
+	assert.NoError(t, validateConfiguration(c))
+}
+
+func TestConfigureOpentelemetryE(t *testing.T) {
+	_, err := ConfigureOpentelemetryE(WithSpanExporterEndpoint(""), WithMetricsEnabled(false))
+	assert.EqualError(t, err, "configuration error: invalid configuration: service name missing. Configure WithServiceName in code")
+
+	ls, err := ConfigureOpentelemetryE(WithServiceName("api"), WithSpanExporterEndpoint(""), WithMetricsEnabled(false))
+	require.NoError(t, err)
+	ls.Shutdown()
+}
+
+func TestWithExportRetry(t *testing.T) {
+	c, err := newConfig(WithServiceName("api"))
+	require.NoError(t, err)
+	assert.Nil(t, c.ExportRetry)
+
+	c, err = newConfig(WithServiceName("api"), WithExportRetry(true, time.Second, 10*time.Second, 2*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, &ExportRetry{
+		Enabled:         true,
+		InitialInterval: time.Second,
+		MaxInterval:     10 * time.Second,
+		MaxElapsedTime:  2 * time.Minute,
+	}, c.ExportRetry)
+}
+
+func TestShutdownE(t *testing.T) {
+	ls := Launcher{pipelines: []*pipeline{
+		{signal: "traces", shutdown: func(context.Context) error { return errors.New("deadline exceeded") }},
+		{signal: "metrics", shutdown: func(context.Context) error { return errors.New("connection closed") }},
+	}}
+	err := ls.ShutdownE(context.Background())
+	assert.EqualError(t, err, "stopping traces: deadline exceeded; stopping metrics: connection closed")
+}
+
+func TestShutdownEConcurrent(t *testing.T) {
+	ls := Launcher{
+		config: Config{ShutdownTimeout: 50 * time.Millisecond},
+		pipelines: []*pipeline{
+			{signal: "traces", shutdown: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}},
+			{signal: "metrics", shutdown: func(context.Context) error { return nil }},
+		},
+	}
+	start := time.Now()
+	err := ls.ShutdownE(context.Background())
+	assert.EqualError(t, err, "stopping traces: context deadline exceeded")
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestForceFlush(t *testing.T) {
+	var flushed []string
+	ls := Launcher{pipelines: []*pipeline{
+		{signal: "traces", flush: func(context.Context) error { flushed = append(flushed, "traces"); return nil }},
+		{signal: "metrics", flush: func(context.Context) error { return errors.New("controller stopped") }},
+	}}
+	err := ls.ForceFlush(context.Background())
+	assert.EqualError(t, err, "flushing metrics: controller stopped")
+	assert.Equal(t, []string{"traces"}, flushed)
+}
+
+func TestShutdownFuncs(t *testing.T) {
+	var calls []string
+	record := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+	ls := Launcher{
+		lifecycle: &lifecycle{},
+		pipelines: []*pipeline{
+			{signal: "traces", shutdown: record("traces")},
+		},
+	}
+	ls.AddShutdownFunc(record("flush cache"))
+	ls.AddShutdownFunc(record("record final metrics"))
+	ls.AddAfterShutdownFunc(record("close log file"))
+
+	require.NoError(t, ls.ShutdownE(context.Background()))
+	assert.Equal(t, []string{"record final metrics", "flush cache", "traces", "close log file"}, calls)
+}
+
+func TestShutdownReport(t *testing.T) {
+	var exported, failed int64 = 10, 1
+	ls := Launcher{pipelines: []*pipeline{
+		{
+			signal: "traces",
+			shutdown: func(context.Context) error {
+				exported += 3
+				failed += 2
+				return errors.New("deadline exceeded")
+			},
+			stats: func() (int64, int64) { return exported, failed },
+		},
+		{signal: "metrics", shutdown: func(context.Context) error { return nil }},
+	}}
+
+	report := ls.ShutdownReport(context.Background())
+	assert.EqualError(t, report.Err, "stopping traces: deadline exceeded")
+	require.Len(t, report.Pipelines, 2)
+	assert.Equal(t, "traces", report.Pipelines[0].Component)
+	assert.Equal(t, int64(3), report.Pipelines[0].Flushed)
+	assert.Equal(t, int64(2), report.Pipelines[0].Dropped)
+	assert.EqualError(t, report.Pipelines[0].Err, "stopping traces: deadline exceeded")
+	assert.Equal(t, PipelineShutdown{Component: "metrics", Duration: report.Pipelines[1].Duration}, report.Pipelines[1])
+}
@@ -0,0 +1,92 @@
+package launcher
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/multierr"
+)
+
+// lifecycle holds the functions registered to run when a launcher is shut
+// down. It is shared by copies of the Launcher.
+type lifecycle struct {
+	mu     sync.Mutex
+	before []func(context.Context) error
+	after  []func(context.Context) error
+}
+
+// WithOnStart registers fn to be called with the final configuration once
+// the pipelines have started.
+func WithOnStart(fn func(Config)) Option {
+	return func(c *Config) {
+		c.onStart = append(c.onStart, fn)
+	}
+}
+
+// WithBeforeSetup registers fn to be called with the final configuration
+// before the pipelines are started, such as to enforce platform policy.
+// If fn returns an error, ConfigureOpentelemetryE returns it without
+// starting the pipelines. Functions are called in the order they were
+// registered.
+func WithBeforeSetup(fn func(Config) error) Option {
+	return func(c *Config) {
+		c.beforeSetup = append(c.beforeSetup, fn)
+	}
+}
+
+// WithAfterSetup registers fn to be called once the pipelines have
+// started, such as to register additional instrumentation or to publish
+// readiness. If fn returns an error, the launcher is shut down and
+// ConfigureOpentelemetryE returns the error. Functions are called in the
+// order they were registered, before those registered with WithOnStart.
+func WithAfterSetup(fn func(Launcher) error) Option {
+	return func(c *Config) {
+		c.afterSetup = append(c.afterSetup, fn)
+	}
+}
+
+// AddShutdownFunc registers fn to be called when the launcher is shut
+// down, before the pipelines are stopped, so that any telemetry it records,
+// such as final metric values, is exported. Functions are called in the
+// reverse order in which they were added.
+func (ls Launcher) AddShutdownFunc(fn func(context.Context) error) {
+	if ls.lifecycle == nil {
+		return
+	}
+	ls.lifecycle.mu.Lock()
+	defer ls.lifecycle.mu.Unlock()
+	ls.lifecycle.before = append(ls.lifecycle.before, fn)
+}
+
+// AddAfterShutdownFunc registers fn to be called once the pipelines have
+// been stopped. Functions are called in the reverse order in which they
+// were added.
+func (ls Launcher) AddAfterShutdownFunc(fn func(context.Context) error) {
+	if ls.lifecycle == nil {
+		return
+	}
+	ls.lifecycle.mu.Lock()
+	defer ls.lifecycle.mu.Unlock()
+	ls.lifecycle.after = append(ls.lifecycle.after, fn)
+}
+
+// runShutdownFuncs calls the functions registered to run before the
+// pipelines are stopped, or after them, and returns the combined errors.
+func (ls Launcher) runShutdownFuncs(ctx context.Context, after bool) error {
+	if ls.lifecycle == nil {
+		return nil
+	}
+	ls.lifecycle.mu.Lock()
+	fns := ls.lifecycle.before
+	if after {
+		fns = ls.lifecycle.after
+	}
+	fns = append([]func(context.Context) error(nil), fns...)
+	ls.lifecycle.mu.Unlock()
+
+	var err error
+	for i := len(fns) - 1; i >= 0; i-- {
+		err = multierr.Append(err, fns[i](ctx))
+	}
+	return err
+}
@@ -0,0 +1,54 @@
+package launcher
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/multierr"
+)
+
+// ExporterUpdate changes the endpoints and headers of the exporters of a
+// running Launcher. Empty fields are left unchanged.
+type ExporterUpdate struct {
+	SpanExporterEndpoint   string
+	MetricExporterEndpoint string
+	// Headers are merged over the current headers of both exporters, so
+	// that a rotated token can be updated without repeating the others.
+	Headers map[string]string
+}
+
+// UpdateExporters changes the endpoints and headers of the exporters
+// without restarting the pipelines. Each exporter dials a new connection
+// and replaces the previous one once it has been created, after any
+// exports in progress have completed, so no telemetry is dropped. If an
+// exporter can't be updated it keeps its previous configuration.
+func (ls Launcher) UpdateExporters(ctx context.Context, u ExporterUpdate) error {
+	c := ls.config
+	if u.SpanExporterEndpoint != "" {
+		c.SpanExporterEndpoint = u.SpanExporterEndpoint
+	}
+	if u.MetricExporterEndpoint != "" {
+		c.MetricExporterEndpoint = u.MetricExporterEndpoint
+	}
+	if err := validateConfiguration(c); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+	var err error
+	for _, p := range ls.pipelines {
+		endpoint := u.SpanExporterEndpoint
+		if p.signal == "metrics" {
+			endpoint = u.MetricExporterEndpoint
+		}
+		if p.update == nil || (endpoint == "" && len(u.Headers) == 0) {
+			continue
+		}
+		if uerr := p.update(ctx, u); uerr != nil {
+			err = multierr.Append(err, fmt.Errorf("updating %s exporter: %w", p.signal, uerr))
+			continue
+		}
+		if endpoint != "" {
+			p.setEndpoint(endpoint)
+		}
+	}
+	return err
+}
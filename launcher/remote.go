@@ -0,0 +1,160 @@
+package launcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// RemoteConfig is configuration managed centrally and pushed to the
+// launcher by a RemoteConfigSource. Unset fields leave the local
+// configuration in effect.
+type RemoteConfig struct {
+	// SamplingRatio is the ratio of root spans sampled, between 0 and 1.
+	// Child spans follow the sampling decision of their parent.
+	SamplingRatio *float64 `json:"sampling_ratio,omitempty"`
+	// TracesEnabled and MetricsEnabled disable the export of a signal when
+	// false. A signal which is disabled locally can't be enabled remotely.
+	TracesEnabled  *bool `json:"traces_enabled,omitempty"`
+	MetricsEnabled *bool `json:"metrics_enabled,omitempty"`
+	// DroppedAttributes are the keys of span attributes which are removed
+	// before export.
+	DroppedAttributes []string `json:"dropped_attributes,omitempty"`
+}
+
+// ParseRemoteConfig parses a RemoteConfig encoded as JSON, for sources
+// which receive the configuration as a document.
+func ParseRemoteConfig(b []byte) (RemoteConfig, error) {
+	var rc RemoteConfig
+	if err := json.Unmarshal(b, &rc); err != nil {
+		return RemoteConfig{}, fmt.Errorf("invalid remote configuration: %w", err)
+	}
+	if r := rc.SamplingRatio; r != nil && (*r < 0 || *r > 1) {
+		return RemoteConfig{}, fmt.Errorf("invalid remote configuration: sampling ratio %v is not between 0 and 1", *r)
+	}
+	return rc, nil
+}
+
+// RemoteConfigSource delivers remote configuration to the launcher. The
+// opamp package provides a source which polls an OpAMP server; services
+// can also implement a source over their own management channel.
+type RemoteConfigSource interface {
+	// Subscribe calls update with each configuration received, until ctx
+	// is done or the source fails. Each configuration replaces the
+	// previous one.
+	Subscribe(ctx context.Context, update func(RemoteConfig)) error
+}
+
+// WithRemoteConfig applies configuration pushed by source to the running
+// pipelines. The fields set in overrides take
+// precedence over the remote configuration, so that a service can pin
+// settings locally. Until a configuration is received the local
+// configuration is used, and if the source fails the last configuration
+// received remains in effect while the launcher subscribes again.
+func WithRemoteConfig(source RemoteConfigSource, overrides RemoteConfig) Option {
+	return func(c *Config) {
+		c.remoteConfig = newRemoteConfig(source, overrides)
+	}
+}
+
+// remoteConfig applies the configuration received from a source.
+type remoteConfig struct {
+	source    RemoteConfigSource
+	overrides RemoteConfig
+	state     atomic.Value // remoteState
+}
+
+// remoteState is the configuration in effect, resolved so that it is cheap
+// to read for every span.
+type remoteState struct {
+	sampler         trace.Sampler
+	tracesDisabled  bool
+	metricsDisabled bool
+	dropped         map[attribute.Key]struct{}
+}
+
+func newRemoteConfig(source RemoteConfigSource, overrides RemoteConfig) *remoteConfig {
+	rc := &remoteConfig{source: source, overrides: overrides}
+	rc.update(RemoteConfig{})
+	return rc
+}
+
+func (rc *remoteConfig) load() remoteState {
+	return rc.state.Load().(remoteState)
+}
+
+// update replaces the configuration in effect with cfg, merged under the
+// local overrides.
+func (rc *remoteConfig) update(cfg RemoteConfig) {
+	if rc.overrides.SamplingRatio != nil {
+		cfg.SamplingRatio = rc.overrides.SamplingRatio
+	}
+	if rc.overrides.TracesEnabled != nil {
+		cfg.TracesEnabled = rc.overrides.TracesEnabled
+	}
+	if rc.overrides.MetricsEnabled != nil {
+		cfg.MetricsEnabled = rc.overrides.MetricsEnabled
+	}
+	if rc.overrides.DroppedAttributes != nil {
+		cfg.DroppedAttributes = rc.overrides.DroppedAttributes
+	}
+	var s remoteState
+	if cfg.SamplingRatio != nil {
+		s.sampler = trace.ParentBased(trace.TraceIDRatioBased(*cfg.SamplingRatio))
+	}
+	s.tracesDisabled = cfg.TracesEnabled != nil && !*cfg.TracesEnabled
+	s.metricsDisabled = cfg.MetricsEnabled != nil && !*cfg.MetricsEnabled
+	if len(cfg.DroppedAttributes) > 0 {
+		s.dropped = make(map[attribute.Key]struct{}, len(cfg.DroppedAttributes))
+		for _, k := range cfg.DroppedAttributes {
+			s.dropped[attribute.Key(k)] = struct{}{}
+		}
+	}
+	rc.state.Store(s)
+}
+
+func (rc *remoteConfig) droppedAttributes() map[attribute.Key]struct{} {
+	return rc.load().dropped
+}
+
+func (rc *remoteConfig) metricsPaused() bool {
+	return rc.load().metricsDisabled
+}
+
+// remoteSampler samples with the sampler of the remote configuration, or
+// the local sampler if it doesn't set one, and drops every span if the
+// remote configuration disables traces.
+type remoteSampler struct {
+	local  trace.Sampler
+	remote *remoteConfig
+}
+
+func (s remoteSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	state := s.remote.load()
+	if state.tracesDisabled {
+		return trace.SamplingResult{
+			Decision:   trace.Drop,
+			Tracestate: oteltrace.SpanContextFromContext(p.ParentContext).TraceState(),
+		}
+	}
+	if state.sampler != nil {
+		return state.sampler.ShouldSample(p)
+	}
+	return s.local.ShouldSample(p)
+}
+
+func (s remoteSampler) Description() string {
+	state := s.remote.load()
+	switch {
+	case state.tracesDisabled:
+		return "Remote{AlwaysOffSampler}"
+	case state.sampler != nil:
+		return "Remote{" + state.sampler.Description() + "}"
+	}
+	return "Remote{" + s.local.Description() + "}"
+}
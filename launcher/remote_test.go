@@ -0,0 +1,70 @@
+//go:build !noop
+// +build !noop
+
+package launcher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+type channelSource chan RemoteConfig
+
+func (s channelSource) Subscribe(ctx context.Context, update func(RemoteConfig)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case cfg := <-s:
+			update(cfg)
+		}
+	}
+}
+
+func TestRemoteConfig(t *testing.T) {
+	rc, err := ParseRemoteConfig([]byte(`{"sampling_ratio": 0, "metrics_enabled": false, "dropped_attributes": ["user.email"]}`))
+	require.NoError(t, err)
+	_, err = ParseRemoteConfig([]byte(`{"sampling_ratio": 2}`))
+	assert.EqualError(t, err, "invalid remote configuration: sampling ratio 2 is not between 0 and 1")
+
+	disabled := false
+	remote := newRemoteConfig(nil, RemoteConfig{MetricsEnabled: &disabled, DroppedAttributes: []string{"http.user_agent"}})
+	sampler := remoteSampler{local: trace.AlwaysSample(), remote: remote}
+	params := trace.SamplingParameters{ParentContext: context.Background(), TraceID: oteltrace.TraceID{0xff}}
+	assert.Equal(t, trace.RecordAndSample, sampler.ShouldSample(params).Decision)
+	assert.Equal(t, "Remote{AlwaysOnSampler}", sampler.Description())
+	assert.True(t, remote.metricsPaused())
+
+	remote.update(rc)
+	assert.Equal(t, trace.Drop, sampler.ShouldSample(params).Decision)
+	assert.Equal(t, "Remote{ParentBased{root:TraceIDRatioBased{0},remoteParentSampled:AlwaysOnSampler,remoteParentNotSampled:AlwaysOffSampler,localParentSampled:AlwaysOnSampler,localParentNotSampled:AlwaysOffSampler}}", sampler.Description())
+	assert.Equal(t, map[attribute.Key]struct{}{"http.user_agent": {}}, remote.droppedAttributes())
+
+	enabled := true
+	remote.update(RemoteConfig{TracesEnabled: &disabled, MetricsEnabled: &enabled})
+	assert.Equal(t, trace.Drop, sampler.ShouldSample(params).Decision)
+	assert.True(t, remote.metricsPaused())
+}
+
+func TestWithRemoteConfig(t *testing.T) {
+	source := make(channelSource)
+	ls, err := ConfigureOpentelemetryE(
+		WithServiceName("api"),
+		WithSpanExporterEndpoint(""),
+		WithMetricsEnabled(false),
+		WithRemoteConfig(source, RemoteConfig{}),
+	)
+	require.NoError(t, err)
+	disabled := false
+	// The second send is received once the first has been applied.
+	source <- RemoteConfig{TracesEnabled: &disabled}
+	source <- RemoteConfig{TracesEnabled: &disabled}
+	assert.Equal(t, "Remote{AlwaysOffSampler}", ls.Status().Sampler)
+	require.NoError(t, ls.ShutdownE(context.Background()))
+}
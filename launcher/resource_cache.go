@@ -0,0 +1,186 @@
+//go:build !noop
+// +build !noop
+
+package launcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// DefaultResourceCacheTTL is how long cached resource detection results
+// are used when WithResourceCache is given no TTL.
+const DefaultResourceCacheTTL = time.Hour
+
+// WithResourceCache caches the results of resource detection in the file
+// at path, so that processes on the same host which use the same
+// detectors, such as the EC2 or Kubernetes detectors, don't each repeat
+// the detection on startup. Cached results are used until they are older
+// than ttl, or the configured detectors change. Detection which fails is
+// not cached. Use InvalidateResourceCache to force detection to run again.
+func WithResourceCache(path string, ttl time.Duration) Option {
+	return func(c *Config) {
+		c.ResourceCachePath = path
+		c.ResourceCacheTTL = ttl
+	}
+}
+
+// InvalidateResourceCache removes the resource cache at path, so that the
+// next launcher to start runs resource detection.
+func InvalidateResourceCache(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// resourceCache is the file format of the resource cache.
+type resourceCache struct {
+	Key        string            `json:"key"`
+	Detected   time.Time         `json:"detected"`
+	Attributes []cachedAttribute `json:"attributes"`
+}
+
+type cachedAttribute struct {
+	Key   string      `json:"key"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// cachedDetector runs detectors, caching the detected resource at path.
+type cachedDetector struct {
+	detectors []resource.Detector
+	path      string
+	ttl       time.Duration
+	now       func() time.Time
+}
+
+func newCachedDetector(detectors []resource.Detector, path string, ttl time.Duration) cachedDetector {
+	if ttl <= 0 {
+		ttl = DefaultResourceCacheTTL
+	}
+	return cachedDetector{detectors: detectors, path: path, ttl: ttl, now: time.Now}
+}
+
+// key identifies the configured detectors, so that a cache written by a
+// process with different detectors is not used.
+func (d cachedDetector) key() string {
+	types := make([]string, len(d.detectors))
+	for i, det := range d.detectors {
+		if s, ok := det.(schemalessDetector); ok {
+			det = s.Detector
+		}
+		types[i] = fmt.Sprintf("%T", det)
+	}
+	return strings.Join(types, ",")
+}
+
+func (d cachedDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	if r, ok := d.load(); ok {
+		return r, nil
+	}
+	// Errors are reported as partial, so that the resources of the
+	// detectors which succeeded are still used.
+	r, err := resource.New(ctx, resource.WithDetectors(d.detectors...))
+	if err != nil {
+		return r, fmt.Errorf("%w: %v", resource.ErrPartialResource, err)
+	}
+	if err := d.store(r); err != nil {
+		return r, fmt.Errorf("%w: writing resource cache: %v", resource.ErrPartialResource, err)
+	}
+	return r, nil
+}
+
+// load returns the cached resource, if it is present and current.
+func (d cachedDetector) load() (*resource.Resource, bool) {
+	b, err := ioutil.ReadFile(d.path)
+	if err != nil {
+		return nil, false
+	}
+	var cache resourceCache
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&cache); err != nil {
+		return nil, false
+	}
+	if cache.Key != d.key() || d.now().Sub(cache.Detected) > d.ttl {
+		return nil, false
+	}
+	attrs := make([]attribute.KeyValue, 0, len(cache.Attributes))
+	for _, a := range cache.Attributes {
+		kv, ok := a.keyValue()
+		if !ok {
+			return nil, false
+		}
+		attrs = append(attrs, kv)
+	}
+	return resource.NewSchemaless(attrs...), true
+}
+
+// store writes r to the cache. The cache is replaced atomically, so that
+// concurrently starting processes never read a partial file.
+func (d cachedDetector) store(r *resource.Resource) error {
+	cache := resourceCache{Key: d.key(), Detected: d.now()}
+	for _, kv := range r.Attributes() {
+		switch kv.Value.Type() {
+		case attribute.BOOL, attribute.INT64, attribute.FLOAT64, attribute.STRING:
+			cache.Attributes = append(cache.Attributes, cachedAttribute{
+				Key:   string(kv.Key),
+				Type:  kv.Value.Type().String(),
+				Value: kv.Value.AsInterface(),
+			})
+		default:
+			// Slice values are not cached, so the resource is detected
+			// again next time.
+			return nil
+		}
+	}
+	b, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(d.path), filepath.Base(d.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), d.path)
+}
+
+func (a cachedAttribute) keyValue() (attribute.KeyValue, bool) {
+	key := attribute.Key(a.Key)
+	switch a.Type {
+	case attribute.BOOL.String():
+		v, ok := a.Value.(bool)
+		return key.Bool(v), ok
+	case attribute.INT64.String():
+		n, _ := a.Value.(json.Number)
+		v, err := n.Int64()
+		return key.Int64(v), err == nil
+	case attribute.FLOAT64.String():
+		n, _ := a.Value.(json.Number)
+		v, err := n.Float64()
+		return key.Float64(v), err == nil
+	case attribute.STRING.String():
+		v, ok := a.Value.(string)
+		return key.String(v), ok
+	}
+	return attribute.KeyValue{}, false
+}
@@ -0,0 +1,75 @@
+//go:build !noop
+// +build !noop
+
+package launcher
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+type countingDetector struct {
+	testDetector
+	calls *int
+}
+
+func (d countingDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	*d.calls++
+	return d.testDetector.Detect(ctx)
+}
+
+func TestResourceCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resource-cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "resource.json")
+
+	calls := 0
+	detector := countingDetector{
+		testDetector: testDetector{attrs: []attribute.KeyValue{
+			attribute.String("cloud.region", "ap-southeast-2"),
+			attribute.Int64("faas.max_memory", 1<<40+1),
+		}},
+		calls: &calls,
+	}
+	now := time.Now()
+	d := newCachedDetector([]resource.Detector{schemalessDetector{detector}}, path, time.Minute)
+	d.now = func() time.Time { return now }
+
+	detect := func() []attribute.KeyValue {
+		r, err := d.Detect(context.Background())
+		require.NoError(t, err)
+		return r.Attributes()
+	}
+	want := []attribute.KeyValue{
+		attribute.String("cloud.region", "ap-southeast-2"),
+		attribute.Int64("faas.max_memory", 1<<40+1),
+	}
+	assert.Equal(t, want, detect())
+	assert.Equal(t, want, detect())
+	assert.Equal(t, 1, calls)
+
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, want, detect())
+	assert.Equal(t, 2, calls)
+
+	require.NoError(t, InvalidateResourceCache(path))
+	assert.Equal(t, want, detect())
+	assert.Equal(t, 3, calls)
+
+	failing := newCachedDetector([]resource.Detector{testDetector{err: errors.New("metadata unavailable")}}, path, time.Minute)
+	_, err = failing.Detect(context.Background())
+	assert.True(t, errors.Is(err, resource.ErrPartialResource))
+	assert.Equal(t, want, detect())
+	assert.Equal(t, 3, calls)
+}
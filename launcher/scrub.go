@@ -0,0 +1,20 @@
+package launcher
+
+import "regexp"
+
+// Patterns for common personal data and secrets, for use with WithScrubbing.
+var (
+	EmailPattern       = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	BearerTokenPattern = regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9\-._~+/]+=*`)
+	SSNPattern         = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+)
+
+// WithScrubbing replaces text matching any of the patterns with
+// [REDACTED] in span names, string attribute values, event names and
+// attributes, and status descriptions, before spans are exported or passed
+// to span end hooks.
+func WithScrubbing(patterns ...*regexp.Regexp) Option {
+	return func(c *Config) {
+		c.ScrubPatterns = append(c.ScrubPatterns, patterns...)
+	}
+}
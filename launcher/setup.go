@@ -0,0 +1,390 @@
+//go:build !noop
+// +build !noop
+
+package launcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/common-fate/observability/pipelines"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
+	"google.golang.org/grpc/connectivity"
+)
+
+func setupTracing(c Config) (*pipeline, error) {
+	if c.testExporter != nil {
+		return c.testExporter.setupTracing(c), nil
+	}
+	if c.SpanExporterEndpoint == "" {
+		c.logger.Debug("tracing is disabled by configuration: no endpoint set")
+		return nil, nil
+	}
+	if c.testCoordinator != nil {
+		return c.testCoordinator.register(c), nil
+	}
+	var dynamicResource pipelines.DynamicResourceAttributes
+	if c.DynamicResource != nil {
+		dynamicResource = c.DynamicResource.ResourceAttributes
+	}
+	p, err := pipelines.NewTracePipeline(c.context, pipelines.PipelineConfig{
+		Endpoint:                       c.SpanExporterEndpoint,
+		Insecure:                       c.SpanExporterEndpointInsecure,
+		TLSConfig:                      c.TLSConfig,
+		CACertificateFile:              c.CACertificateFile,
+		ClientCertificateFile:          c.ClientCertificateFile,
+		ClientKeyFile:                  c.ClientKeyFile,
+		Headers:                        c.Headers,
+		CredentialsRefresher:           pipelines.CredentialsRefresher(c.CredentialsRefresher),
+		Resource:                       c.Resource,
+		Propagators:                    c.Propagators,
+		TextMapPropagators:             c.TextMapPropagators,
+		Sampler:                        c.Sampler,
+		BatchTimeout:                   c.BatchTimeout,
+		BatchSpanProcessorOptions:      c.BatchSpanProcessorOptions,
+		MaxExportStaleness:             c.MaxExportStaleness,
+		DiskBufferDir:                  c.DiskBufferDir,
+		DiskBufferMaxBytes:             c.DiskBufferMaxBytes,
+		BiasedSampling:                 c.BiasedSampling,
+		BiasedSamplingRatio:            c.BiasedSamplingRatio,
+		BiasedSamplingLatencyThreshold: c.BiasedSamplingLatencyThreshold,
+		OperationSLAs:                  c.OperationSLAs,
+		SpanMetrics:                    c.SpanMetrics,
+		SpanCostMetrics:                c.SpanCostMetrics,
+		SelfMetrics:                    c.SelfMetrics,
+		ResourceFromSpanAttributes:     c.ResourceFromSpanAttributes,
+		DynamicResourceAttributes:      dynamicResource,
+		SpanStartHooks:                 spanStartHooks(c.SpanStartHooks),
+		SpanEndHooks:                   spanEndHooks(c.SpanEndHooks),
+		ReconnectBackoff:               c.ReconnectBackoff,
+		Keepalive:                      c.Keepalive,
+		ExportRetry:                    exportRetry(c.ExportRetry),
+		DialOptions:                    c.DialOptions,
+		ProxyURL:                       c.ProxyURL,
+		SyncExport:                     c.Lambda,
+		MaxExportBatchBytes:            c.MaxExportBatchBytes,
+		SpanLimits:                     sdkSpanLimits(c.SpanLimits),
+		AttributeValueLengthLimit:      c.SpanLimits.AttributeValueLength,
+		MaxSpanDepth:                   c.MaxSpanDepth,
+		PrioritySpan:                   c.PrioritySpan,
+		ExportInspector:                exportInspector(c.ExportInspector),
+		ScrubPatterns:                  c.ScrubPatterns,
+		DroppedSpans:                   spanMatchers(c.DroppedSpans),
+		AttributeFilter:                remoteAttributeFilter(c.remoteConfig),
+		OnConnectionStateChange: func(s connectivity.State) {
+			c.exporterStates.set("traces", s.String())
+		},
+	})
+	return newPipeline("traces", p, err)
+}
+
+func setupMetrics(c Config) (*pipeline, error) {
+	if !c.MetricsEnabled {
+		c.logger.Debug("metrics are disabled by configuration: no endpoint set")
+		return nil, nil
+	}
+	if c.testExporter != nil {
+		return c.testExporter.setupMetrics(c), nil
+	}
+	if c.testCoordinator != nil {
+		c.logger.Debug("metrics are disabled: registered with a test coordinator")
+		return nil, nil
+	}
+	p, err := pipelines.NewMetricsPipeline(c.context, pipelines.PipelineConfig{
+		Endpoint:                    c.MetricExporterEndpoint,
+		Insecure:                    c.MetricExporterEndpointInsecure,
+		TLSConfig:                   c.TLSConfig,
+		CACertificateFile:           c.CACertificateFile,
+		ClientCertificateFile:       c.ClientCertificateFile,
+		ClientKeyFile:               c.ClientKeyFile,
+		Headers:                     c.Headers,
+		CredentialsRefresher:        pipelines.CredentialsRefresher(c.CredentialsRefresher),
+		Resource:                    c.Resource,
+		ReportingPeriod:             c.MetricReportingPeriod,
+		MetricTemporality:           c.MetricTemporality,
+		MetricViews:                 metricViews(c.MetricViews),
+		HistogramBuckets:            c.HistogramBuckets,
+		HostMetrics:                 c.HostMetrics,
+		SelfMetrics:                 c.SelfMetrics,
+		CardinalityLimit:            c.CardinalityLimit,
+		InstrumentCardinalityLimits: c.InstrumentCardinalityLimits,
+		ExportInspector:             exportInspector(c.ExportInspector),
+		LoadSignal:                  c.LoadShedding.Signal,
+		LoadThreshold:               c.LoadShedding.Threshold,
+		CriticalInstruments:         c.LoadShedding.Critical,
+		LoadSheddingDownsampleEvery: c.LoadShedding.DownsampleEvery,
+		BatchTimeout:                c.BatchTimeout,
+		ReconnectBackoff:            c.ReconnectBackoff,
+		Keepalive:                   c.Keepalive,
+		ExportRetry:                 exportRetry(c.ExportRetry),
+		DialOptions:                 c.DialOptions,
+		ProxyURL:                    c.ProxyURL,
+		MetricsPaused:               remoteMetricsPaused(c.remoteConfig),
+		OnConnectionStateChange: func(s connectivity.State) {
+			c.exporterStates.set("metrics", s.String())
+		},
+	})
+	return newPipeline("metrics", p, err)
+}
+
+// ingestPolicyTimeout bounds the startup handshake with the ingest service.
+const ingestPolicyTimeout = 5 * time.Second
+
+// applyIngestPolicy fetches the policy of the tenant from the ingest
+// service and applies it to c. An error is returned only if c does not
+// meet the policy.
+func applyIngestPolicy(c *Config) error {
+	if !c.IngestPolicy || c.SpanExporterEndpoint == "" || c.testCoordinator != nil || c.testExporter != nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(c.context, ingestPolicyTimeout)
+	defer cancel()
+	p, err := pipelines.FetchIngestPolicy(ctx, connectionConfig(c, c.SpanExporterEndpoint, c.SpanExporterEndpointInsecure))
+	if err != nil {
+		c.logger.Sugar().Warnf("using local configuration: %v", err)
+		return nil
+	}
+	for _, h := range p.RequiredHeaders {
+		if !hasHeader(c.Headers, h) {
+			return fmt.Errorf("ingest policy requires the %s header", h)
+		}
+	}
+	if n := p.MaxAttributesPerSpan; n > 0 && (c.SpanLimits.AttributeCount == 0 || c.SpanLimits.AttributeCount > n) {
+		c.SpanLimits.AttributeCount = n
+	}
+	if p.SamplingRatio > 0 && c.Sampler == nil {
+		c.Sampler = trace.ParentBased(trace.TraceIDRatioBased(p.SamplingRatio))
+	}
+	return nil
+}
+
+// hasHeader reports whether headers contains name, which like all gRPC
+// metadata keys is case insensitive.
+func hasHeader(headers map[string]string, name string) bool {
+	for k := range headers {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func spanStartHooks(hooks []func(context.Context, oteltrace.Span)) []pipelines.SpanStartHook {
+	out := make([]pipelines.SpanStartHook, len(hooks))
+	for i, h := range hooks {
+		out[i] = h
+	}
+	return out
+}
+
+func spanEndHooks(hooks []func(trace.ReadOnlySpan)) []pipelines.SpanEndHook {
+	out := make([]pipelines.SpanEndHook, len(hooks))
+	for i, h := range hooks {
+		out[i] = h
+	}
+	return out
+}
+
+func metricViews(views []MetricView) []pipelines.MetricView {
+	out := make([]pipelines.MetricView, len(views))
+	for i, v := range views {
+		out[i] = pipelines.MetricView(v)
+	}
+	return out
+}
+
+func sdkSpanLimits(l SpanLimits) trace.SpanLimits {
+	return trace.SpanLimits{
+		AttributeCountLimit:         l.AttributeCount,
+		EventCountLimit:             l.EventCount,
+		LinkCountLimit:              l.LinkCount,
+		AttributePerEventCountLimit: l.AttributePerEventCount,
+		AttributePerLinkCountLimit:  l.AttributePerLinkCount,
+	}
+}
+
+func spanMatchers(matchers []SpanMatcher) []pipelines.SpanMatcher {
+	out := make([]pipelines.SpanMatcher, len(matchers))
+	for i, m := range matchers {
+		out[i] = pipelines.SpanMatcher(m)
+	}
+	return out
+}
+
+func exportInspector(inspect func(ExportSummary)) pipelines.ExportInspector {
+	if inspect == nil {
+		return nil
+	}
+	return func(s pipelines.ExportSummary) {
+		inspect(ExportSummary(s))
+	}
+}
+
+func newPipeline(signal string, p *pipelines.Pipeline, err error) (*pipeline, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &pipeline{
+		signal:        signal,
+		shutdown:      p.Shutdown,
+		flush:         p.ForceFlush,
+		forwardTraces: p.ForwardTraces,
+		stats: func() (int64, int64) {
+			s := p.Stats()
+			return s.Exported, s.Failed
+		},
+		status: func() exportStatus {
+			s := p.Stats()
+			return exportStatus{
+				exported:      s.Exported,
+				failed:        s.Failed,
+				lastError:     s.LastError,
+				lastErrorTime: s.LastErrorTime,
+			}
+		},
+		update: func(ctx context.Context, u ExporterUpdate) error {
+			endpoint := u.SpanExporterEndpoint
+			if signal == "metrics" {
+				endpoint = u.MetricExporterEndpoint
+			}
+			return p.Update(ctx, pipelines.ExporterUpdate{Endpoint: endpoint, Headers: u.Headers})
+		},
+	}, nil
+}
+
+// exportRetry converts the retry configuration of the launcher to that of
+// the pipelines.
+func exportRetry(r *ExportRetry) *pipelines.RetryConfig {
+	if r == nil {
+		return nil
+	}
+	rc := pipelines.RetryConfig(*r)
+	return &rc
+}
+
+// connectionConfig returns the configuration of a connection to the
+// collector at endpoint, for calls made outside the pipelines.
+func connectionConfig(c *Config, endpoint string, insecure bool) pipelines.PipelineConfig {
+	return pipelines.PipelineConfig{
+		Endpoint:              endpoint,
+		Insecure:              insecure,
+		TLSConfig:             c.TLSConfig,
+		CACertificateFile:     c.CACertificateFile,
+		ClientCertificateFile: c.ClientCertificateFile,
+		ClientKeyFile:         c.ClientKeyFile,
+		Headers:               c.Headers,
+		CredentialsRefresher:  pipelines.CredentialsRefresher(c.CredentialsRefresher),
+		ReconnectBackoff:      c.ReconnectBackoff,
+		Keepalive:             c.Keepalive,
+		DialOptions:           c.DialOptions,
+		ProxyURL:              c.ProxyURL,
+	}
+}
+
+// probeEndpoints checks that the trace and metric endpoints are reachable
+// and accept the configured headers, within c.StartupProbeTimeout.
+func probeEndpoints(c Config) error {
+	if c.testCoordinator != nil || c.testExporter != nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(c.context, c.StartupProbeTimeout)
+	defer cancel()
+	var err error
+	if c.SpanExporterEndpoint != "" {
+		err = multierr.Append(err, pipelines.ProbeTraceEndpoint(ctx, connectionConfig(&c, c.SpanExporterEndpoint, c.SpanExporterEndpointInsecure)))
+	}
+	if c.MetricsEnabled && c.MetricExporterEndpoint != "" {
+		err = multierr.Append(err, pipelines.ProbeMetricEndpoint(ctx, connectionConfig(&c, c.MetricExporterEndpoint, c.MetricExporterEndpointInsecure)))
+	}
+	return err
+}
+
+func remoteAttributeFilter(rc *remoteConfig) pipelines.AttributeFilter {
+	if rc == nil {
+		return nil
+	}
+	return rc.droppedAttributes
+}
+
+func remoteMetricsPaused(rc *remoteConfig) func() bool {
+	if rc == nil {
+		return nil
+	}
+	return rc.metricsPaused
+}
+
+// remoteConfigRetryInterval is how long the launcher waits to subscribe
+// again after the remote configuration source fails.
+const remoteConfigRetryInterval = 30 * time.Second
+
+// start subscribes to the source until the launcher is shut down.
+func (rc *remoteConfig) start(ls Launcher) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			err := rc.source.Subscribe(ctx, rc.update)
+			if ctx.Err() != nil {
+				return
+			}
+			ls.config.logger.Sugar().Warnf("remote configuration unavailable, keeping the current configuration: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(remoteConfigRetryInterval):
+			}
+		}
+	}()
+	ls.AddAfterShutdownFunc(func(context.Context) error {
+		cancel()
+		wg.Wait()
+		return nil
+	})
+}
+
+// shutdownOnSignal shuts down the launcher on the first of signals, then
+// restores the default handling and raises the signal again. The signals
+// are no longer handled once the launcher has been shut down.
+func (ls Launcher) shutdownOnSignal(signals []os.Signal) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	done := make(chan struct{})
+	var once sync.Once
+	ls.AddAfterShutdownFunc(func(context.Context) error {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+		return nil
+	})
+
+	go func() {
+		var sig os.Signal
+		select {
+		case sig = <-ch:
+		case <-done:
+			return
+		}
+		ls.config.logger.Sugar().Debugf("shutting down on %v", sig)
+		// Shutting down stops the signals being handled.
+		ls.ShutdownContext(context.Background())
+
+		p, err := os.FindProcess(os.Getpid())
+		if err == nil {
+			err = p.Signal(sig)
+		}
+		if err != nil {
+			// Some platforms, such as Windows, cannot raise every signal.
+			os.Exit(1)
+		}
+	}()
+}
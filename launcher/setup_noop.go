@@ -0,0 +1,33 @@
+//go:build noop
+// +build noop
+
+package launcher
+
+import "os"
+
+// When built with the noop tag the launcher keeps its public API but never
+// creates exporters or registers SDK providers. The global OpenTelemetry
+// providers remain the default no-op implementations, so the otelchi and
+// otelgrpc helpers also become no-ops.
+
+func setupTracing(c Config) (*pipeline, error) {
+	c.logger.Debug("tracing is disabled: built with the noop tag")
+	return nil, nil
+}
+
+func setupMetrics(c Config) (*pipeline, error) {
+	c.logger.Debug("metrics are disabled: built with the noop tag")
+	return nil, nil
+}
+
+func applyIngestPolicy(c *Config) error {
+	return nil
+}
+
+func probeEndpoints(c Config) error {
+	return nil
+}
+
+func (rc *remoteConfig) start(ls Launcher) {}
+
+func (ls Launcher) shutdownOnSignal(signals []os.Signal) {}
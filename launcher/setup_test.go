@@ -0,0 +1,295 @@
+//go:build !noop
+// +build !noop
+
+package launcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/common-fate/observability/pipelines"
+	"github.com/common-fate/observability/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestDebugHandler(t *testing.T) {
+	failedAt := time.Now()
+	ls := Launcher{
+		config: Config{
+			SpanExporterEndpoint: "collector.internal:4317",
+			Resource:             resource.NewSchemaless(attribute.String("service.name", "api")),
+			Sampler:              sdktrace.TraceIDRatioBased(0.5),
+			exporterStates:       newExporterStates(),
+		},
+	}
+	p, err := newPipeline("traces", &pipelines.Pipeline{
+		Stats: func() pipelines.ExportStats {
+			return pipelines.ExportStats{Exported: 90, Failed: 4, Queued: 100, LastError: errors.New("unavailable"), LastErrorTime: failedAt}
+		},
+	}, nil)
+	require.NoError(t, err)
+	ls.pipelines = []*pipeline{p}
+	ls.config.exporterStates.set("traces", "READY")
+
+	rec := httptest.NewRecorder()
+	ls.DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/telemetry", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var s Status
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &s))
+	assert.Equal(t, "api", s.Resource["service.name"])
+	assert.Equal(t, "TraceIDRatioBased{0.5}", s.Sampler)
+	traces := s.Pipelines["traces"]
+	assert.True(t, traces.Enabled)
+	assert.Equal(t, "collector.internal:4317", traces.Endpoint)
+	assert.Equal(t, "READY", traces.State)
+	assert.Equal(t, int64(90), traces.Exported)
+	assert.Equal(t, int64(4), traces.Failed)
+	assert.Equal(t, "unavailable", traces.LastError)
+	require.NotNil(t, traces.LastErrorTime)
+	assert.True(t, failedAt.Equal(*traces.LastErrorTime))
+	assert.False(t, s.Pipelines["metrics"].Enabled)
+}
+
+func TestStatusZeroLauncher(t *testing.T) {
+	var ls Launcher
+	assert.Empty(t, ls.ExporterState())
+	assert.False(t, ls.Status().Pipelines["traces"].Enabled)
+}
+
+// recordingCollector records the x-api-key header and spans of each export.
+type recordingCollector struct {
+	coltracepb.UnimplementedTraceServiceServer
+	mu    sync.Mutex
+	keys  []string
+	spans []*tracepb.Span
+}
+
+func (c *recordingCollector) Export(ctx context.Context, req *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keys = append(c.keys, strings.Join(md.Get("x-api-key"), ","))
+	for _, rs := range req.ResourceSpans {
+		for _, ils := range rs.InstrumentationLibrarySpans {
+			c.spans = append(c.spans, ils.Spans...)
+		}
+	}
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+func (c *recordingCollector) received() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.keys...)
+}
+
+func (c *recordingCollector) receivedSpans() []*tracepb.Span {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*tracepb.Span(nil), c.spans...)
+}
+
+func serveCollector(t *testing.T) (*recordingCollector, string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	c := &recordingCollector{}
+	srv := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(srv, c)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	return c, lis.Addr().String()
+}
+
+func TestUpdateExporters(t *testing.T) {
+	first, firstAddr := serveCollector(t)
+	second, secondAddr := serveCollector(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ls, err := ConfigureOpentelemetryE(
+		WithServiceName("api"),
+		WithSpanExporterEndpoint(firstAddr),
+		WithSpanExporterInsecure(true),
+		WithHeaders(map[string]string{"x-api-key": "expired"}),
+		WithMetricsEnabled(false),
+	)
+	require.NoError(t, err)
+	defer ls.Shutdown()
+	export := func() {
+		_, span := otel.Tracer("test").Start(ctx, "GET /users")
+		span.End()
+		require.NoError(t, ls.ForceFlush(ctx))
+	}
+
+	export()
+	assert.Equal(t, []string{"expired"}, first.received())
+
+	require.NoError(t, ls.UpdateExporters(ctx, ExporterUpdate{Headers: map[string]string{"x-api-key": "rotated"}}))
+	export()
+	assert.Equal(t, []string{"expired", "rotated"}, first.received())
+
+	require.NoError(t, ls.UpdateExporters(ctx, ExporterUpdate{SpanExporterEndpoint: secondAddr}))
+	export()
+	assert.Len(t, first.received(), 2)
+	assert.Equal(t, []string{"rotated"}, second.received())
+	assert.Equal(t, secondAddr, ls.Status().Pipelines["traces"].Endpoint)
+}
+
+func TestMaxSpanDepthWithProfilerLabels(t *testing.T) {
+	collector, addr := serveCollector(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ls, err := ConfigureOpentelemetryE(
+		WithServiceName("api"),
+		WithSpanExporterEndpoint(addr),
+		WithSpanExporterInsecure(true),
+		WithMetricsEnabled(false),
+		WithMaxSpanDepth(2),
+		WithProfilerLabels(true),
+	)
+	require.NoError(t, err)
+	defer ls.Shutdown()
+	defer tracing.SetProfilerLabels(false)
+
+	rootCtx, root := tracing.Start(ctx, "resolve")
+	level2Ctx, level2 := tracing.Start(rootCtx, "resolve.1")
+	_, level3 := tracing.Start(level2Ctx, "resolve.2")
+	level3.End()
+	level2.End()
+	root.End()
+	require.NoError(t, ls.ForceFlush(ctx))
+
+	spans := collector.receivedSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "resolve.1", spans[0].Name)
+	require.Len(t, spans[0].Events, 1)
+	assert.Equal(t, "resolve.2", spans[0].Events[0].Name)
+}
+
+func TestFailureMode(t *testing.T) {
+	opts := []Option{
+		WithServiceName("api"),
+		WithSpanExporterEndpoint("localhost:4317"),
+		WithSpanExporterInsecure(true),
+		WithMetricsEnabled(false),
+		WithPropagators([]string{"unknown"}),
+	}
+	ls := ConfigureOpentelemetry(append(opts, WithFailureMode(FailOpen))...)
+	assert.Empty(t, ls.pipelines)
+	ls.Shutdown()
+
+	_, err := ConfigureOpentelemetryE(append(opts, WithFailureMode(FailClosed))...)
+	require.Error(t, err)
+	ls = Launcher{config: Config{FailureMode: FailClosed, logger: *zap.NewNop()}}
+	assert.False(t, ls.failOpen(err))
+	ls.config.FailureMode = FailOpen
+	assert.True(t, ls.failOpen(err))
+	assert.False(t, ls.failOpen(errors.New("configuration error: service name missing")))
+}
+
+// blockingSource is a RemoteConfigSource which never sends a configuration.
+type blockingSource struct{}
+
+func (blockingSource) Subscribe(ctx context.Context, _ func(RemoteConfig)) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestForwardTracesFilters(t *testing.T) {
+	collector, addr := serveCollector(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ls, err := ConfigureOpentelemetryE(
+		WithServiceName("api"),
+		WithSpanExporterEndpoint(addr),
+		WithSpanExporterInsecure(true),
+		WithMetricsEnabled(false),
+		WithRemoteConfig(blockingSource{}, RemoteConfig{DroppedAttributes: []string{"user.email"}}),
+		WithDroppedSpans(SpanMatcher{Name: "healthcheck"}),
+	)
+	require.NoError(t, err)
+	defer ls.Shutdown()
+
+	span := func(name string, spanID byte) *tracepb.Span {
+		return &tracepb.Span{
+			TraceId: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			SpanId:  []byte{spanID, 0, 0, 0, 0, 0, 0, 1},
+			Name:    name,
+			Attributes: []*commonpb.KeyValue{
+				{Key: "user.email", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "jo@example.com"}}},
+				{Key: "policy", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "allow"}}},
+			},
+		}
+	}
+	require.NoError(t, ls.ForwardTraces(ctx, &tracepb.ResourceSpans{
+		InstrumentationLibrarySpans: []*tracepb.InstrumentationLibrarySpans{{
+			Spans: []*tracepb.Span{span("evaluate", 1), span("healthcheck", 2)},
+		}},
+	}))
+	require.NoError(t, ls.ForceFlush(ctx))
+
+	spans := collector.receivedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "evaluate", spans[0].Name)
+	var keys []string
+	for _, kv := range spans[0].Attributes {
+		keys = append(keys, kv.Key)
+	}
+	assert.Equal(t, []string{"policy"}, keys)
+}
+
+func TestShutdownOnSignalStopsAfterShutdown(t *testing.T) {
+	// Receiving the signal here as well keeps it from terminating the test
+	// binary.
+	ch := make(chan os.Signal, 2)
+	signal.Notify(ch, os.Interrupt)
+	defer signal.Stop(ch)
+
+	ls := ConfigureOpentelemetry(WithServiceName("api"), WithOfflineMode(true), WithShutdownOnSignal(os.Interrupt))
+	shutdowns := 0
+	ls.AddShutdownFunc(func(context.Context) error {
+		shutdowns++
+		return nil
+	})
+	ls.Shutdown()
+
+	p, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	if err := p.Signal(os.Interrupt); err != nil {
+		t.Skipf("cannot raise interrupt: %v", err)
+	}
+	<-ch
+	// A launcher still handling the signal would shut down again and raise
+	// it a second time.
+	select {
+	case <-ch:
+		t.Fatal("signal handled after shutdown")
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.Equal(t, 1, shutdowns)
+}
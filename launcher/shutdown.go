@@ -0,0 +1,94 @@
+package launcher
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+)
+
+// ShutdownReport describes the shutdown of a launcher.
+type ShutdownReport struct {
+	// Pipelines describes the shutdown of each pipeline, in the order they
+	// were started.
+	Pipelines []PipelineShutdown
+	// Duration is how long the shutdown took, including the functions
+	// added with AddShutdownFunc and AddAfterShutdownFunc.
+	Duration time.Duration
+	// Err combines the errors of the pipelines and shutdown functions.
+	Err error
+}
+
+// PipelineShutdown describes the shutdown of a single pipeline.
+type PipelineShutdown struct {
+	// Component is the signal exported by the pipeline, "traces" or
+	// "metrics".
+	Component string
+	// Flushed is the number of spans or metric records exported while the
+	// pipeline was shut down.
+	Flushed int64
+	// Dropped is the number of spans or metric records which failed to
+	// export while the pipeline was shut down.
+	Dropped int64
+	// Duration is how long the pipeline took to shut down.
+	Duration time.Duration
+	// Err is the error stopping the pipeline, if any.
+	Err error
+}
+
+// ShutdownReport flushes and stops every pipeline like ShutdownE, and
+// reports what each pipeline flushed or dropped, so that callers can log
+// the outcome or assert on it in tests.
+func (ls Launcher) ShutdownReport(ctx context.Context) ShutdownReport {
+	start := time.Now()
+	if ls.config.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ls.config.ShutdownTimeout)
+		defer cancel()
+	}
+	var report ShutdownReport
+	err := ls.runShutdownFuncs(ctx, false)
+	report.Pipelines = ls.shutdownPipelines(ctx)
+	for _, p := range report.Pipelines {
+		err = multierr.Append(err, p.Err)
+	}
+	report.Err = multierr.Append(err, ls.runShutdownFuncs(ctx, true))
+	report.Duration = time.Since(start)
+	return report
+}
+
+// shutdownPipelines stops the pipelines concurrently.
+func (ls Launcher) shutdownPipelines(ctx context.Context) []PipelineShutdown {
+	reports := make([]PipelineShutdown, len(ls.pipelines))
+	var wg sync.WaitGroup
+	for i, p := range ls.pipelines {
+		wg.Add(1)
+		go func(i int, p *pipeline) {
+			defer wg.Done()
+			reports[i] = p.stop(ctx)
+		}(i, p)
+	}
+	wg.Wait()
+	return reports
+}
+
+// stop shuts down p, counting the items exported while it flushes.
+func (p *pipeline) stop(ctx context.Context) PipelineShutdown {
+	report := PipelineShutdown{Component: p.signal}
+	var exported, failed int64
+	if p.stats != nil {
+		exported, failed = p.stats()
+	}
+	start := time.Now()
+	if err := p.shutdown(ctx); err != nil {
+		report.Err = fmt.Errorf("stopping %s: %w", p.signal, err)
+	}
+	report.Duration = time.Since(start)
+	if p.stats != nil {
+		e, f := p.stats()
+		report.Flushed, report.Dropped = e-exported, f-failed
+	}
+	return report
+}
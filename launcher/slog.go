@@ -0,0 +1,116 @@
+//go:build go1.21
+// +build go1.21
+
+package launcher
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewSlogHandler returns an slog.Handler which records log entries as
+// OpenTelemetry log events on the span active in the context passed to the
+// logger, and forwards them to next with trace_id and span_id attributes
+// attached. next may be nil, in which case entries at slog.LevelInfo and
+// above are only recorded on spans.
+//
+//	logger := slog.New(launcher.NewSlogHandler(slog.NewJSONHandler(os.Stderr, nil)))
+//	logger.InfoContext(ctx, "granted access", "grant.id", grantID)
+//
+// As with NewZapOtelCore, records are exported with the trace pipeline as
+// span events, since the OpenTelemetry Go SDK used by this package does not
+// provide a logs signal.
+func NewSlogHandler(next slog.Handler) slog.Handler {
+	return &slogHandler{next: next}
+}
+
+type slogHandler struct {
+	next   slog.Handler
+	prefix string
+	attrs  []attribute.KeyValue
+}
+
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.next == nil {
+		return level >= slog.LevelInfo
+	}
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	span := trace.SpanFromContext(ctx)
+	if span.IsRecording() {
+		attrs := append([]attribute.KeyValue{
+			attribute.String("log.severity", r.Level.String()),
+			attribute.String("log.message", r.Message),
+		}, h.attrs...)
+		r.Attrs(func(a slog.Attr) bool {
+			attrs = appendSlogAttr(attrs, h.prefix, a)
+			return true
+		})
+		span.AddEvent("log", trace.WithTimestamp(r.Time), trace.WithAttributes(attrs...))
+	}
+
+	if h.next == nil {
+		return nil
+	}
+	if sc := span.SpanContext(); sc.IsValid() {
+		r = r.Clone()
+		r.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = h.attrs[:len(h.attrs):len(h.attrs)]
+	for _, a := range attrs {
+		h2.attrs = appendSlogAttr(h2.attrs, h.prefix, a)
+	}
+	if h.next != nil {
+		h2.next = h.next.WithAttrs(attrs)
+	}
+	return &h2
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	if h.next != nil {
+		h2.next = h.next.WithGroup(name)
+	}
+	return &h2
+}
+
+// appendSlogAttr converts a, flattening groups into dotted keys.
+func appendSlogAttr(attrs []attribute.KeyValue, prefix string, a slog.Attr) []attribute.KeyValue {
+	v := a.Value.Resolve()
+	key := prefix + a.Key
+	switch v.Kind() {
+	case slog.KindGroup:
+		if a.Key != "" {
+			prefix = key + "."
+		}
+		for _, ga := range v.Group() {
+			attrs = appendSlogAttr(attrs, prefix, ga)
+		}
+		return attrs
+	case slog.KindBool:
+		return append(attrs, attribute.Bool(key, v.Bool()))
+	case slog.KindInt64:
+		return append(attrs, attribute.Int64(key, v.Int64()))
+	case slog.KindFloat64:
+		return append(attrs, attribute.Float64(key, v.Float64()))
+	default:
+		return append(attrs, attribute.String(key, v.String()))
+	}
+}
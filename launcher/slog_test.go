@@ -0,0 +1,38 @@
+//go:build go1.21
+// +build go1.21
+
+package launcher
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSlogHandler(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	ctx, span := tp.Tracer("test").Start(context.Background(), "request")
+
+	var buf bytes.Buffer
+	logger := slog.New(NewSlogHandler(slog.NewJSONHandler(&buf, nil)))
+	logger.With("grant", "gr_123").WithGroup("req").InfoContext(ctx, "granted access", "id", 7)
+	span.End()
+
+	assert.Contains(t, buf.String(), `"trace_id":"`+span.SpanContext().TraceID().String()+`"`)
+	assert.Contains(t, buf.String(), `"span_id":"`+span.SpanContext().SpanID().String()+`"`)
+
+	require.Len(t, sr.Ended(), 1)
+	events := sr.Ended()[0].Events()
+	require.Len(t, events, 1)
+	assert.Contains(t, events[0].Attributes, attribute.String("log.message", "granted access"))
+	assert.Contains(t, events[0].Attributes, attribute.String("grant", "gr_123"))
+	assert.Contains(t, events[0].Attributes, attribute.Int64("req.id", 7))
+}
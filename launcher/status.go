@@ -0,0 +1,106 @@
+package launcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Status describes the telemetry the process reports about itself: the
+// resolved resource, the sampler and the state of each pipeline. It is
+// intended to be embedded in a service's /version or /debug endpoint.
+type Status struct {
+	Resource  map[string]string         `json:"resource"`
+	Sampler   string                    `json:"sampler"`
+	Pipelines map[string]PipelineStatus `json:"pipelines"`
+}
+
+// PipelineStatus describes the pipeline for a single signal.
+type PipelineStatus struct {
+	Enabled  bool       `json:"enabled"`
+	Endpoint string     `json:"endpoint,omitempty"`
+	State    string     `json:"state,omitempty"`
+	Since    *time.Time `json:"since,omitempty"`
+	// Exported and Failed count the spans or metric records the pipeline
+	// has exported, and failed to export, since it started.
+	Exported      int64      `json:"exported"`
+	Failed        int64      `json:"failed"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorTime *time.Time `json:"last_error_time,omitempty"`
+}
+
+// Status returns the resolved resource and pipeline status.
+func (ls Launcher) Status() Status {
+	s := Status{
+		Resource: make(map[string]string),
+		Sampler:  describeSampler(ls.config),
+		Pipelines: map[string]PipelineStatus{
+			"traces":  {Endpoint: ls.config.SpanExporterEndpoint},
+			"metrics": {Endpoint: ls.config.MetricExporterEndpoint},
+		},
+	}
+	if ls.config.Resource != nil {
+		for _, kv := range ls.config.Resource.Attributes() {
+			s.Resource[string(kv.Key)] = kv.Value.Emit()
+		}
+	}
+	for _, lp := range ls.pipelines {
+		p := s.Pipelines[lp.signal]
+		p.Enabled = true
+		if endpoint := lp.updatedEndpoint(); endpoint != "" {
+			p.Endpoint = endpoint
+		}
+		if lp.status != nil {
+			es := lp.status()
+			p.Exported, p.Failed = es.exported, es.failed
+			if es.lastError != nil {
+				t := es.lastErrorTime
+				p.LastError = es.lastError.Error()
+				p.LastErrorTime = &t
+			}
+		}
+		s.Pipelines[lp.signal] = p
+	}
+	for signal, state := range ls.ExporterState() {
+		p := s.Pipelines[signal]
+		since := state.Since
+		p.State = state.State
+		p.Since = &since
+		s.Pipelines[signal] = p
+	}
+	return s
+}
+
+// StatusJSON returns Status encoded as indented JSON.
+func (ls Launcher) StatusJSON() ([]byte, error) {
+	return json.MarshalIndent(ls.Status(), "", "  ")
+}
+
+// DebugHandler returns an http.Handler which serves the Status as JSON, for
+// diagnosing where a service's telemetry is going. It is not registered
+// anywhere by default, and should be served on an internal port as the
+// status includes the collector endpoints and resource attributes.
+func (ls Launcher) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ls.StatusJSON()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(b)
+	})
+}
+
+// describeSampler describes the sampler configured in c.
+func describeSampler(c Config) string {
+	desc := "AlwaysOnSampler"
+	if c.Sampler != nil {
+		desc = c.Sampler.Description()
+	}
+	if c.BiasedSampling {
+		desc += fmt.Sprintf(", biased sampling {ratio:%g, latency threshold:%s}", c.BiasedSamplingRatio, c.BiasedSamplingLatencyThreshold)
+	}
+	return desc
+}
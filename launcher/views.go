@@ -0,0 +1,57 @@
+package launcher
+
+// Aggregations which a MetricView can select.
+const (
+	AggregationDefault   = ""
+	AggregationSum       = "sum"
+	AggregationLastValue = "last_value"
+	AggregationHistogram = "histogram"
+	AggregationDrop      = "drop"
+)
+
+// MetricView changes how the instruments whose name matches Instrument, a
+// pattern in the syntax of path.Match, are exported.
+type MetricView struct {
+	Instrument string
+	// Name renames the instrument, unless it is empty.
+	Name string
+	// AttributeKeys lists the attributes which are kept, unless it is nil.
+	// Measurements which only differ in the attributes removed are
+	// aggregated together.
+	AttributeKeys []string
+	// Aggregation is one of the Aggregation constants.
+	Aggregation string
+	// HistogramBuckets are the bucket boundaries used with
+	// AggregationHistogram, or the SDK defaults if empty.
+	HistogramBuckets []float64
+}
+
+// WithMetricViews changes how instruments are exported: renaming them,
+// removing attributes, or changing their aggregation. Only the first view
+// matching an instrument is applied. For example,
+//
+//	launcher.WithMetricViews(
+//		launcher.MetricView{Instrument: "http.server.duration", AttributeKeys: []string{"http.route", "http.status_code"}},
+//		launcher.MetricView{Instrument: "runtime.go.gc.*", Aggregation: launcher.AggregationDrop},
+//	)
+func WithMetricViews(views ...MetricView) Option {
+	return func(c *Config) {
+		c.MetricViews = append(c.MetricViews, views...)
+	}
+}
+
+// WithHistogramBuckets exports the histogram instrument with the given name
+// as a histogram with explicit bucket boundaries, such as
+//
+//	launcher.WithHistogramBuckets("http.server.duration", []float64{5, 10, 25, 50, 100, 250, 500, 1000})
+//
+// By default histogram instruments are only exported as sums and counts.
+// The buckets take precedence over the aggregation selected by a view.
+func WithHistogramBuckets(instrument string, buckets []float64) Option {
+	return func(c *Config) {
+		if c.HistogramBuckets == nil {
+			c.HistogramBuckets = make(map[string][]float64)
+		}
+		c.HistogramBuckets[instrument] = buckets
+	}
+}
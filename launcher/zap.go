@@ -0,0 +1,130 @@
+package launcher
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const zapContextKey = "context"
+
+// ZapContext returns a zap field carrying ctx. The field is not encoded by
+// other cores; it allows the core returned by NewZapOtelCore to attach the
+// log entry to the span active in ctx.
+//
+//	logger.Info("granted access", launcher.ZapContext(ctx))
+func ZapContext(ctx context.Context) zap.Field {
+	return zap.Field{Key: zapContextKey, Type: zapcore.SkipType, Interface: ctx}
+}
+
+// TraceFields returns zap fields identifying the span active in ctx, for
+// correlating log lines with traces. It returns no fields if ctx does not
+// carry a valid span context.
+func TraceFields(ctx context.Context) []zap.Field {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []zap.Field{
+		zap.String("trace_id", sc.TraceID().String()),
+		zap.String("span_id", sc.SpanID().String()),
+	}
+}
+
+// LoggerFromContext returns the global zap logger, which is used by the
+// launcher, decorated with the trace_id and span_id of the span active in
+// ctx. The logger also carries ctx as a ZapContext field, so entries are
+// attached to the span by NewZapOtelCore.
+func LoggerFromContext(ctx context.Context) *zap.Logger {
+	return zap.L().With(append(TraceFields(ctx), ZapContext(ctx))...)
+}
+
+// NewZapOtelCore returns a zapcore.Core which records log entries as
+// OpenTelemetry log events on the span carried by a ZapContext field.
+// Entries without a recording span are discarded, so the core is intended
+// to be combined with an existing core using zapcore.NewTee.
+//
+// The OpenTelemetry Go SDK used by this package does not provide a logs
+// signal, so records are exported with the trace pipeline as span events.
+func NewZapOtelCore(enab zapcore.LevelEnabler) zapcore.Core {
+	return &otelCore{LevelEnabler: enab}
+}
+
+type otelCore struct {
+	zapcore.LevelEnabler
+	fields []zapcore.Field
+}
+
+func (c *otelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &otelCore{
+		LevelEnabler: c.LevelEnabler,
+		fields:       append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+func (c *otelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *otelCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	all := append(c.fields[:len(c.fields):len(c.fields)], fields...)
+
+	var span trace.Span
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range all {
+		if f.Key == zapContextKey && f.Type == zapcore.SkipType {
+			if ctx, ok := f.Interface.(context.Context); ok {
+				span = trace.SpanFromContext(ctx)
+			}
+			continue
+		}
+		f.AddTo(enc)
+	}
+	if span == nil || !span.IsRecording() {
+		return nil
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("log.severity", ent.Level.CapitalString()),
+		attribute.String("log.message", ent.Message),
+	}
+	if ent.LoggerName != "" {
+		attrs = append(attrs, attribute.String("log.logger", ent.LoggerName))
+	}
+	if ent.Caller.Defined {
+		attrs = append(attrs, attribute.String("code.filepath", ent.Caller.File), attribute.Int("code.lineno", ent.Caller.Line))
+	}
+	for k, v := range enc.Fields {
+		attrs = append(attrs, zapFieldAttribute(k, v))
+	}
+	span.AddEvent("log", trace.WithTimestamp(ent.Time), trace.WithAttributes(attrs...))
+	return nil
+}
+
+func (c *otelCore) Sync() error {
+	return nil
+}
+
+func zapFieldAttribute(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
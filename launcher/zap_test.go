@@ -0,0 +1,46 @@
+package launcher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestZapOtelCore(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	ctx, span := tp.Tracer("test").Start(context.Background(), "request")
+
+	logger := zap.New(NewZapOtelCore(zapcore.InfoLevel))
+	logger.Debug("ignored", ZapContext(ctx))
+	logger.Info("no context")
+	logger.Info("granted access", ZapContext(ctx), zap.String("grant.id", "gr_123"))
+	span.End()
+
+	require.Len(t, sr.Ended(), 1)
+	events := sr.Ended()[0].Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "log", events[0].Name)
+	assert.Contains(t, events[0].Attributes, attribute.String("log.message", "granted access"))
+	assert.Contains(t, events[0].Attributes, attribute.String("log.severity", "INFO"))
+	assert.Contains(t, events[0].Attributes, attribute.String("grant.id", "gr_123"))
+}
+
+func TestTraceFields(t *testing.T) {
+	tp := sdktrace.NewTracerProvider()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "request")
+	defer span.End()
+
+	assert.Empty(t, TraceFields(context.Background()))
+	assert.Equal(t, []zap.Field{
+		zap.String("trace_id", span.SpanContext().TraceID().String()),
+		zap.String("span_id", span.SpanContext().SpanID().String()),
+	}, TraceFields(ctx))
+}
@@ -0,0 +1,199 @@
+package launchertest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanMatcher matches spans in assertions.
+type SpanMatcher struct {
+	desc  string
+	match func(sdktrace.ReadOnlySpan) bool
+}
+
+func (m SpanMatcher) String() string {
+	return m.desc
+}
+
+// WithName matches spans with the given name.
+func WithName(name string) SpanMatcher {
+	return SpanMatcher{
+		desc:  fmt.Sprintf("name %q", name),
+		match: func(s sdktrace.ReadOnlySpan) bool { return s.Name() == name },
+	}
+}
+
+// WithKind matches spans of the given kind.
+func WithKind(kind trace.SpanKind) SpanMatcher {
+	return SpanMatcher{
+		desc:  fmt.Sprintf("kind %s", kind),
+		match: func(s sdktrace.ReadOnlySpan) bool { return s.SpanKind() == kind },
+	}
+}
+
+// WithAttribute matches spans with the attribute kv.
+func WithAttribute(kv attribute.KeyValue) SpanMatcher {
+	return SpanMatcher{
+		desc: fmt.Sprintf("attribute %s=%s", kv.Key, kv.Value.Emit()),
+		match: func(s sdktrace.ReadOnlySpan) bool {
+			for _, attr := range s.Attributes() {
+				if attr == kv {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// WithStatus matches spans with the given status code.
+func WithStatus(code codes.Code) SpanMatcher {
+	return SpanMatcher{
+		desc:  fmt.Sprintf("status %s", code),
+		match: func(s sdktrace.ReadOnlySpan) bool { return s.Status().Code == code },
+	}
+}
+
+// WithEvent matches spans with an event of the given name.
+func WithEvent(name string) SpanMatcher {
+	return SpanMatcher{
+		desc: fmt.Sprintf("event %q", name),
+		match: func(s sdktrace.ReadOnlySpan) bool {
+			for _, e := range s.Events() {
+				if e.Name == name {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// WithParent matches the children of parent. If parent is nil, as returned
+// by an AssertSpan which failed, no spans match.
+func WithParent(parent sdktrace.ReadOnlySpan) SpanMatcher {
+	if parent == nil {
+		return SpanMatcher{
+			desc:  "parent <nil>",
+			match: func(sdktrace.ReadOnlySpan) bool { return false },
+		}
+	}
+	return SpanMatcher{
+		desc: fmt.Sprintf("parent %q", parent.Name()),
+		match: func(s sdktrace.ReadOnlySpan) bool {
+			return s.Parent().SpanID() == parent.SpanContext().SpanID() && s.Parent().TraceID() == parent.SpanContext().TraceID()
+		},
+	}
+}
+
+// FindSpan returns the first ended span which matches all matchers, or nil
+// if there is none.
+func (r *Recorder) FindSpan(matchers ...SpanMatcher) sdktrace.ReadOnlySpan {
+	for _, s := range r.Spans() {
+		if matchAll(s, matchers) {
+			return s
+		}
+	}
+	return nil
+}
+
+func matchAll(s sdktrace.ReadOnlySpan, matchers []SpanMatcher) bool {
+	for _, m := range matchers {
+		if !m.match(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// AssertSpan asserts that a span matching all matchers has ended, and
+// returns the first such span. Otherwise the test is marked as failed,
+// listing the spans which were recorded, and nil is returned.
+//
+//	span := launchertest.AssertSpan(t, r,
+//		launchertest.WithName("GET /users/{id}"),
+//		launchertest.WithAttribute(semconv.HTTPStatusCodeKey.Int(200)),
+//	)
+func AssertSpan(t testing.TB, r *Recorder, matchers ...SpanMatcher) sdktrace.ReadOnlySpan {
+	t.Helper()
+	if s := r.FindSpan(matchers...); s != nil {
+		return s
+	}
+	descs := make([]string, len(matchers))
+	for i, m := range matchers {
+		descs[i] = m.String()
+	}
+	t.Errorf("no span with %s was recorded. Recorded spans:\n%s", strings.Join(descs, ", "), describeSpans(r.Spans()))
+	return nil
+}
+
+// RequireTraceComplete asserts that every span of the trace with the given
+// ID which was started has ended, and that the spans form a single tree
+// with no missing parents, such as from a context which was not passed
+// on. Spans with a remote parent are roots. Otherwise the test is stopped.
+func RequireTraceComplete(t testing.TB, r *Recorder, traceID trace.TraceID) {
+	t.Helper()
+	ended := make(map[trace.SpanID]sdktrace.ReadOnlySpan)
+	var spans []sdktrace.ReadOnlySpan
+	for _, s := range r.Spans() {
+		if s.SpanContext().TraceID() == traceID {
+			ended[s.SpanContext().SpanID()] = s
+			spans = append(spans, s)
+		}
+	}
+	if len(spans) == 0 {
+		t.Fatalf("trace %s has no ended spans", traceID)
+	}
+
+	var problems []string
+	for _, s := range r.spans.Started() {
+		if s.SpanContext().TraceID() != traceID {
+			continue
+		}
+		if _, ok := ended[s.SpanContext().SpanID()]; !ok {
+			problems = append(problems, fmt.Sprintf("span %q was not ended", s.Name()))
+		}
+	}
+	var roots []string
+	for _, s := range spans {
+		parent := s.Parent()
+		if !parent.IsValid() || parent.IsRemote() {
+			roots = append(roots, fmt.Sprintf("%q", s.Name()))
+			continue
+		}
+		if _, ok := ended[parent.SpanID()]; !ok {
+			problems = append(problems, fmt.Sprintf("the parent %s of span %q was not recorded", parent.SpanID(), s.Name()))
+		}
+	}
+	switch {
+	case len(roots) == 0:
+		problems = append(problems, "trace has no root span")
+	case len(roots) > 1:
+		problems = append(problems, fmt.Sprintf("trace has %d root spans: %s", len(roots), strings.Join(roots, ", ")))
+	}
+	if len(problems) > 0 {
+		t.Fatalf("trace %s is incomplete:\n%s\nRecorded spans:\n%s", traceID, strings.Join(problems, "\n"), describeSpans(spans))
+	}
+}
+
+// describeSpans lists spans with their attributes, one per line.
+func describeSpans(spans []sdktrace.ReadOnlySpan) string {
+	if len(spans) == 0 {
+		return "  (none)"
+	}
+	lines := make([]string, len(spans))
+	for i, s := range spans {
+		attrs := make([]string, len(s.Attributes()))
+		for j, kv := range s.Attributes() {
+			attrs[j] = fmt.Sprintf("%s=%s", kv.Key, kv.Value.Emit())
+		}
+		lines[i] = fmt.Sprintf("  %q kind=%s status=%s {%s}", s.Name(), s.SpanKind(), s.Status().Code, strings.Join(attrs, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
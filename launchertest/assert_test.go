@@ -0,0 +1,93 @@
+//go:build !noop
+// +build !noop
+
+package launchertest
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordingT records the failures of an assertion.
+type recordingT struct {
+	testing.TB
+	failures []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) Fatalf(format string, args ...interface{}) {
+	t.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+// check runs fn with a recordingT, returning its failures.
+func check(t *testing.T, fn func(t testing.TB)) []string {
+	rt := &recordingT{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(rt)
+	}()
+	<-done
+	return rt.failures
+}
+
+func TestAssertSpan(t *testing.T) {
+	r := Configure(t)
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "GET /users/{id}", trace.WithSpanKind(trace.SpanKindServer))
+	_, child := otel.Tracer("test").Start(ctx, "load user", trace.WithAttributes(attribute.String("user.id", "123")))
+	child.SetStatus(codes.Error, "not found")
+	child.End()
+	parent.End()
+
+	server := AssertSpan(t, r, WithName("GET /users/{id}"), WithKind(trace.SpanKindServer))
+	require.NotNil(t, server)
+	AssertSpan(t, r, WithParent(server), WithAttribute(attribute.String("user.id", "123")), WithStatus(codes.Error))
+	RequireTraceComplete(t, r, server.SpanContext().TraceID())
+
+	failures := check(t, func(t testing.TB) {
+		AssertSpan(t, r, WithName("load user"), WithAttribute(attribute.String("user.id", "456")))
+	})
+	assert.Equal(t, []string{`no span with name "load user", attribute user.id=456 was recorded. Recorded spans:
+  "load user" kind=internal status=Error {user.id=123}
+  "GET /users/{id}" kind=server status=Unset {}`}, failures)
+
+	failures = check(t, func(t testing.TB) {
+		AssertSpan(t, r, WithParent(nil))
+	})
+	require.Len(t, failures, 1)
+	assert.Contains(t, failures[0], "no span with parent <nil> was recorded")
+}
+
+func TestRequireTraceComplete(t *testing.T) {
+	r := Configure(t)
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "handle")
+	_, child := otel.Tracer("test").Start(ctx, "query")
+	child.End()
+	traceID := parent.SpanContext().TraceID()
+
+	failures := check(t, func(t testing.TB) {
+		RequireTraceComplete(t, r, traceID)
+	})
+	assert.Equal(t, []string{fmt.Sprintf(`trace %s is incomplete:
+span "handle" was not ended
+the parent %s of span "query" was not recorded
+trace has no root span
+Recorded spans:
+  "query" kind=internal status=Unset {}`, traceID, parent.SpanContext().SpanID())}, failures)
+	parent.End()
+}
@@ -0,0 +1,99 @@
+// Package launchertest configures a launcher which records telemetry in
+// memory, so that services can test their instrumentation without a
+// collector.
+package launchertest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/common-fate/observability/launcher"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/metric/metrictest"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Recorder holds the telemetry recorded by a launcher configured with
+// Configure.
+type Recorder struct {
+	launcher launcher.Launcher
+	spans    *tracetest.SpanRecorder
+	metrics  *metrictest.MeterProvider
+}
+
+// Configure configures OpenTelemetry with opts, recording spans and
+// metrics in memory in place of exporting them, and returns the recorder.
+// The launcher runs offline and its service is named "launchertest"
+// unless opts name it. It is shut down, and the previous global providers
+// restored, when the test ends. As the global providers are replaced,
+// tests which use Configure must not run in parallel.
+func Configure(t testing.TB, opts ...launcher.Option) *Recorder {
+	t.Helper()
+	r := &Recorder{
+		spans:   tracetest.NewSpanRecorder(),
+		metrics: metrictest.NewMeterProvider(),
+	}
+	tp, mp, prop := otel.GetTracerProvider(), global.GetMeterProvider(), otel.GetTextMapPropagator()
+
+	opts = append([]launcher.Option{launcher.WithServiceName("launchertest")}, opts...)
+	opts = append(opts, launcher.WithOfflineMode(true), launcher.WithTestExporter(r.spans, r.metrics))
+	ls, err := launcher.ConfigureOpentelemetryE(opts...)
+	if err != nil {
+		t.Fatalf("configuring launcher: %v", err)
+	}
+	r.launcher = ls
+	if otel.GetTracerProvider() == tp {
+		// Under the noop build tag the launcher installs no providers, so
+		// nothing would be recorded.
+		ls.Shutdown()
+		t.Fatal("launchertest: the launcher installed no tracer provider; launchertest can't be used when built with the noop tag")
+	}
+	t.Cleanup(func() {
+		ls.Shutdown()
+		// The global providers panic if the first provider set is the
+		// default one, so each is only restored if it was replaced.
+		if otel.GetTracerProvider() != tp {
+			otel.SetTracerProvider(tp)
+		}
+		if global.GetMeterProvider() != mp {
+			global.SetMeterProvider(mp)
+		}
+		if !samePropagator(otel.GetTextMapPropagator(), prop) {
+			otel.SetTextMapPropagator(prop)
+		}
+	})
+	return r
+}
+
+// samePropagator reports whether a and b are the same propagator. Composite
+// propagators are slices, which can't be compared with ==.
+func samePropagator(a, b propagation.TextMapPropagator) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Type() != vb.Type() {
+		return false
+	}
+	if va.Type().Comparable() {
+		return a == b
+	}
+	return va.Kind() == reflect.Slice && va.Pointer() == vb.Pointer() && va.Len() == vb.Len()
+}
+
+// Launcher returns the configured launcher.
+func (r *Recorder) Launcher() launcher.Launcher {
+	return r.launcher
+}
+
+// Spans returns the ended spans, in the order they ended.
+func (r *Recorder) Spans() []sdktrace.ReadOnlySpan {
+	return r.spans.Ended()
+}
+
+// Metrics runs the callbacks of the asynchronous instruments, then returns
+// every measurement recorded, in the order they were recorded.
+func (r *Recorder) Metrics() []metrictest.Measured {
+	r.metrics.RunAsyncInstruments()
+	return metrictest.AsStructs(r.metrics.MeasurementBatches)
+}
@@ -0,0 +1,37 @@
+//go:build !noop
+// +build !noop
+
+package launchertest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/global"
+)
+
+func TestConfigure(t *testing.T) {
+	r := Configure(t)
+
+	ctx := context.Background()
+	_, span := otel.Tracer("test").Start(ctx, "get user")
+	span.End()
+	counter := metric.Must(global.Meter("test")).NewInt64Counter("users.created")
+	counter.Add(ctx, 2, attribute.String("tenant", "acme"))
+
+	spans := r.Spans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "get user", spans[0].Name())
+	assert.Contains(t, spans[0].Resource().Attributes(), attribute.String("service.name", "launchertest"))
+
+	metrics := r.Metrics()
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "users.created", metrics[0].Name)
+	assert.Equal(t, int64(2), metrics[0].Number.AsInt64())
+	assert.Equal(t, attribute.StringValue("acme"), metrics[0].Labels["tenant"])
+}
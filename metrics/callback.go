@@ -0,0 +1,191 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/metric/unit"
+)
+
+// Defaults for callback deadline enforcement.
+const (
+	DefaultCallbackTimeout     = time.Second
+	DefaultCallbackMaxTimeouts = 5
+)
+
+var (
+	callbackTimeout     = int64(DefaultCallbackTimeout)
+	callbackMaxTimeouts = int32(DefaultCallbackMaxTimeouts)
+)
+
+// SetCallbackTimeout sets the deadline for the callbacks of observers
+// created by this package, and the number of consecutive timeouts after
+// which a callback is disabled. A maxTimeouts of zero never disables
+// callbacks.
+func SetCallbackTimeout(timeout time.Duration, maxTimeouts int) {
+	atomic.StoreInt64(&callbackTimeout, int64(timeout))
+	atomic.StoreInt32(&callbackMaxTimeouts, int32(maxTimeouts))
+}
+
+// Int64Observe records an observation from an Int64GaugeObserver callback.
+type Int64Observe func(value int64, attrs ...attribute.KeyValue)
+
+// Float64Observe records an observation from a Float64GaugeObserver
+// callback.
+type Float64Observe func(value float64, attrs ...attribute.KeyValue)
+
+// Int64GaugeObserver returns an Int64GaugeObserver registered under name,
+// whose callback is run with a deadline.
+//
+// Observer callbacks are run in turn as metrics are collected, so a
+// callback which blocks, for example on a database query, would otherwise
+// stall the collection of every metric. The callback is passed a context
+// which is cancelled at the deadline, and observations made after the
+// deadline are discarded. A callback which times out repeatedly is
+// disabled and reported to the OpenTelemetry error handler.
+func Int64GaugeObserver(name, unit, description string, callback func(ctx context.Context, observe Int64Observe)) (metric.Int64GaugeObserver, error) {
+	g := &guardedCallback{name: name}
+	inst, err := register(name, "int64_gauge_observer", unit, description, func(m metric.Meter, opts ...metric.InstrumentOption) (interface{}, error) {
+		return m.NewInt64GaugeObserver(name, func(ctx context.Context, result metric.Int64ObserverResult) {
+			for _, o := range g.run(ctx, func(ctx context.Context, observe func(observation)) {
+				callback(ctx, func(value int64, attrs ...attribute.KeyValue) {
+					observe(observation{int64Value: value, attrs: attrs})
+				})
+			}) {
+				result.Observe(o.int64Value, o.attrs...)
+			}
+		}, opts...)
+	})
+	if err != nil {
+		return metric.Int64GaugeObserver{}, err
+	}
+	return inst.(metric.Int64GaugeObserver), nil
+}
+
+// Float64GaugeObserver is like Int64GaugeObserver, for floating point
+// observations.
+func Float64GaugeObserver(name, unit, description string, callback func(ctx context.Context, observe Float64Observe)) (metric.Float64GaugeObserver, error) {
+	g := &guardedCallback{name: name}
+	inst, err := register(name, "float64_gauge_observer", unit, description, func(m metric.Meter, opts ...metric.InstrumentOption) (interface{}, error) {
+		return m.NewFloat64GaugeObserver(name, func(ctx context.Context, result metric.Float64ObserverResult) {
+			for _, o := range g.run(ctx, func(ctx context.Context, observe func(observation)) {
+				callback(ctx, func(value float64, attrs ...attribute.KeyValue) {
+					observe(observation{float64Value: value, attrs: attrs})
+				})
+			}) {
+				result.Observe(o.float64Value, o.attrs...)
+			}
+		}, opts...)
+	})
+	if err != nil {
+		return metric.Float64GaugeObserver{}, err
+	}
+	return inst.(metric.Float64GaugeObserver), nil
+}
+
+type observation struct {
+	int64Value   int64
+	float64Value float64
+	attrs        []attribute.KeyValue
+}
+
+// guardedCallback runs an observer callback with a deadline. Observations
+// are buffered and only returned if the callback completes in time, as the
+// SDK does not allow observations once collection has moved on.
+type guardedCallback struct {
+	name     string
+	timeouts int32
+	disabled int32
+}
+
+func (g *guardedCallback) run(ctx context.Context, fn func(context.Context, func(observation))) []observation {
+	if atomic.LoadInt32(&g.disabled) == 1 {
+		return nil
+	}
+	timeout := time.Duration(atomic.LoadInt64(&callbackTimeout))
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var (
+		mu           sync.Mutex
+		observations []observation
+		closed       bool
+	)
+	observe := func(o observation) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !closed {
+			observations = append(observations, o)
+		}
+	}
+
+	start := time.Now()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(ctx, observe)
+	}()
+
+	attrs := []attribute.KeyValue{attribute.String("callback", g.name)}
+	select {
+	case <-done:
+		callbackInstruments().duration.Record(context.Background(), float64(time.Since(start))/float64(time.Millisecond), attrs...)
+		atomic.StoreInt32(&g.timeouts, 0)
+		mu.Lock()
+		defer mu.Unlock()
+		closed = true
+		return observations
+	case <-ctx.Done():
+		mu.Lock()
+		closed = true
+		mu.Unlock()
+	}
+
+	callbackInstruments().timeouts.Add(context.Background(), 1, attrs...)
+	n := atomic.AddInt32(&g.timeouts, 1)
+	if max := atomic.LoadInt32(&callbackMaxTimeouts); max > 0 && n >= max {
+		atomic.StoreInt32(&g.disabled, 1)
+		otel.Handle(fmt.Errorf("metric callback %s disabled after %d consecutive timeouts of %s", g.name, n, timeout))
+	} else {
+		otel.Handle(fmt.Errorf("metric callback %s timed out after %s", g.name, timeout))
+	}
+	return nil
+}
+
+type callbackMetrics struct {
+	duration metric.Float64Histogram
+	timeouts metric.Int64Counter
+}
+
+var (
+	callbackMetricsOnce sync.Once
+	callbackMetricsInst callbackMetrics
+)
+
+// callbackInstruments returns the instruments describing the callbacks
+// themselves. They are created on first use, so that they are registered
+// with the meter provider configured by the launcher.
+func callbackInstruments() callbackMetrics {
+	callbackMetricsOnce.Do(func() {
+		meter := metric.Must(global.Meter(instrumentationName))
+		callbackMetricsInst = callbackMetrics{
+			duration: meter.NewFloat64Histogram(
+				"metrics.callback.duration",
+				metric.WithDescription("Duration of metric observer callbacks which completed before their deadline"),
+				metric.WithUnit(unit.Milliseconds),
+			),
+			timeouts: meter.NewInt64Counter(
+				"metrics.callback.timeouts",
+				metric.WithDescription("Number of metric observer callbacks which exceeded their deadline"),
+			),
+		}
+	})
+	return callbackMetricsInst
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestGuardedCallback(t *testing.T) {
+	SetCallbackTimeout(20*time.Millisecond, 2)
+	defer SetCallbackTimeout(DefaultCallbackTimeout, DefaultCallbackMaxTimeouts)
+
+	fast := func(ctx context.Context, observe func(observation)) {
+		observe(observation{int64Value: 1, attrs: []attribute.KeyValue{attribute.String("pool", "db")}})
+	}
+	slow := func(ctx context.Context, observe func(observation)) {
+		<-ctx.Done()
+		observe(observation{int64Value: 2})
+	}
+
+	g := &guardedCallback{name: "db.connections"}
+	assert.Len(t, g.run(context.Background(), fast), 1)
+	assert.Empty(t, g.run(context.Background(), slow))
+	// A successful run resets the count of consecutive timeouts.
+	assert.Len(t, g.run(context.Background(), fast), 1)
+	assert.Empty(t, g.run(context.Background(), slow))
+	assert.Empty(t, g.run(context.Background(), slow))
+	// The callback is disabled after two consecutive timeouts.
+	assert.Empty(t, g.run(context.Background(), fast))
+}
@@ -0,0 +1,88 @@
+// Package metrics provides helpers for creating metric instruments with
+// consistent names, units and descriptions across Common Fate services.
+package metrics
+
+import (
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/metric/unit"
+)
+
+const instrumentationName = "github.com/common-fate/observability/metrics"
+
+// instrumentDefinition is the metadata an instrument was first registered with.
+type instrumentDefinition struct {
+	kind        string
+	unit        string
+	description string
+	instrument  interface{}
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]instrumentDefinition{}
+)
+
+// Counter returns an Int64Counter registered under name.
+//
+// The unit must be a valid UCUM unit, such as "ms", "By" or "{requests}".
+// Registering the same name twice with identical metadata returns the
+// existing instrument, while conflicting metadata is an error.
+func Counter(name, unit, description string) (metric.Int64Counter, error) {
+	inst, err := register(name, "counter", unit, description, func(m metric.Meter, opts ...metric.InstrumentOption) (interface{}, error) {
+		return m.NewInt64Counter(name, opts...)
+	})
+	if err != nil {
+		return metric.Int64Counter{}, err
+	}
+	return inst.(metric.Int64Counter), nil
+}
+
+// MustCounter is like Counter but panics if the instrument is invalid.
+// It is intended for package-level instrument declarations, so that
+// conflicting definitions are caught at startup.
+func MustCounter(name, unit, description string) metric.Int64Counter {
+	c, err := Counter(name, unit, description)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func register(name, kind, u, description string, create func(metric.Meter, ...metric.InstrumentOption) (interface{}, error)) (interface{}, error) {
+	if name == "" {
+		return nil, fmt.Errorf("invalid instrument: name missing")
+	}
+	if err := ValidateUnit(u); err != nil {
+		return nil, fmt.Errorf("invalid instrument %q: %w", name, err)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if existing, ok := registry[name]; ok {
+		if existing.kind != kind || existing.unit != u || existing.description != description {
+			return nil, fmt.Errorf("instrument %q already registered as %s with unit %q and description %q", name, existing.kind, existing.unit, existing.description)
+		}
+		return existing.instrument, nil
+	}
+
+	inst, err := create(
+		global.Meter(instrumentationName),
+		metric.WithUnit(unit.Unit(u)),
+		metric.WithDescription(description),
+	)
+	if err != nil {
+		return nil, err
+	}
+	registry[name] = instrumentDefinition{
+		kind:        kind,
+		unit:        u,
+		description: description,
+		instrument:  inst,
+	}
+	return inst, nil
+}
@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateUnit(t *testing.T) {
+	for _, u := range []string{"", "1", "ms", "s", "By", "KiBy", "MBy", "{requests}", "{requests}/s", "By/s", "m2", "s-1", "%", "/s", "By{compressed}"} {
+		assert.NoError(t, ValidateUnit(u), u)
+	}
+	for _, u := range []string{"seconds", "bytes", "kmin", "{requests", "By//s", "ms.", "Kb"} {
+		assert.Error(t, ValidateUnit(u), u)
+	}
+}
+
+func TestCounterConflicts(t *testing.T) {
+	_, err := Counter("test.requests", "{requests}", "Number of requests")
+	assert.NoError(t, err)
+
+	_, err = Counter("test.requests", "{requests}", "Number of requests")
+	assert.NoError(t, err, "identical registrations are permitted")
+
+	_, err = Counter("test.requests", "1", "Number of requests")
+	assert.Error(t, err)
+
+	_, err = Counter("test.bytes", "bytes", "Number of bytes")
+	assert.Error(t, err)
+
+	assert.Panics(t, func() { MustCounter("test.requests", "{requests}", "Another description") })
+}
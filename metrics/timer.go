@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OperationKey is the attribute identifying the operation measured by a
+// Timer.
+const OperationKey = attribute.Key("operation")
+
+const operationDurationName = "operation.duration"
+
+// Timer measures the duration of an operation, recording it in the
+// operation.duration histogram when stopped.
+//
+//	timer := metrics.StartTimer(ctx, "grant.create", metrics.WithSpanEvent())
+//	defer timer.Stop()
+type Timer struct {
+	ctx       context.Context
+	operation string
+	start     time.Time
+	attrs     []attribute.KeyValue
+	spanEvent bool
+	stopped   int32
+}
+
+// TimerOption configures a Timer.
+type TimerOption func(*Timer)
+
+// WithTimerAttributes adds attributes to the recorded duration.
+func WithTimerAttributes(attrs ...attribute.KeyValue) TimerOption {
+	return func(t *Timer) {
+		t.attrs = append(t.attrs, attrs...)
+	}
+}
+
+// WithSpanEvent also adds an event named after the operation, carrying its
+// duration, to the span active in the context passed to StartTimer.
+func WithSpanEvent() TimerOption {
+	return func(t *Timer) {
+		t.spanEvent = true
+	}
+}
+
+// StartTimer starts timing operation.
+func StartTimer(ctx context.Context, operation string, opts ...TimerOption) *Timer {
+	t := &Timer{
+		ctx:       ctx,
+		operation: operation,
+		attrs:     []attribute.KeyValue{OperationKey.String(operation)},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	t.start = time.Now()
+	return t
+}
+
+// Stop records the duration of the operation with any additional
+// attributes, such as the outcome, and returns it. Only the first call to
+// Stop is recorded, so it is safe to defer Stop and also call it early.
+func (t *Timer) Stop(attrs ...attribute.KeyValue) time.Duration {
+	d := time.Since(t.start)
+	if !atomic.CompareAndSwapInt32(&t.stopped, 0, 1) {
+		return d
+	}
+	all := append(t.attrs[:len(t.attrs):len(t.attrs)], attrs...)
+	if h, err := operationDuration(); err != nil {
+		otel.Handle(err)
+	} else {
+		h.Record(t.ctx, float64(d)/float64(time.Millisecond), all...)
+	}
+	if t.spanEvent {
+		trace.SpanFromContext(t.ctx).AddEvent(t.operation, trace.WithAttributes(
+			append(all, attribute.Float64("duration_ms", float64(d)/float64(time.Millisecond)))...,
+		))
+	}
+	return d
+}
+
+var (
+	operationDurationOnce sync.Once
+	operationDurationInst metric.Float64Histogram
+	operationDurationErr  error
+)
+
+// operationDuration returns the histogram recorded by timers. It is
+// created on first use, so that it is registered with the meter provider
+// configured by the launcher.
+func operationDuration() (metric.Float64Histogram, error) {
+	operationDurationOnce.Do(func() {
+		var inst interface{}
+		inst, operationDurationErr = register(operationDurationName, "float64_histogram", "ms", "Duration of operations measured with metrics.StartTimer", func(m metric.Meter, opts ...metric.InstrumentOption) (interface{}, error) {
+			return m.NewFloat64Histogram(operationDurationName, opts...)
+		})
+		if operationDurationErr == nil {
+			operationDurationInst = inst.(metric.Float64Histogram)
+		}
+	})
+	return operationDurationInst, operationDurationErr
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTimer(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	ctx, span := tp.Tracer("test").Start(context.Background(), "handler")
+
+	timer := StartTimer(ctx, "grant.create", WithSpanEvent(), WithTimerAttributes(attribute.String("provider", "okta")))
+	d := timer.Stop(attribute.String("status", "ok"))
+	assert.Positive(t, int64(d))
+	// Later calls do not record again.
+	timer.Stop()
+	span.End()
+
+	require.Len(t, sr.Ended(), 1)
+	events := sr.Ended()[0].Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "grant.create", events[0].Name)
+	assert.Contains(t, events[0].Attributes, OperationKey.String("grant.create"))
+	assert.Contains(t, events[0].Attributes, attribute.String("provider", "okta"))
+	assert.Contains(t, events[0].Attributes, attribute.String("status", "ok"))
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+)
+
+// metricAtoms are UCUM units which may be combined with a metric prefix.
+var metricAtoms = map[string]bool{
+	"s": true, "m": true, "g": true, "l": true, "L": true,
+	"By": true, "bit": true, "Hz": true, "J": true, "W": true,
+	"A": true, "V": true, "K": true, "mol": true, "Pa": true,
+	"N": true, "Bd": true, "t": true,
+}
+
+// nonMetricAtoms are UCUM units which must not carry a prefix.
+var nonMetricAtoms = map[string]bool{
+	"1": true, "%": true, "min": true, "h": true, "d": true,
+	"wk": true, "mo": true, "a": true, "Cel": true, "[degF]": true,
+}
+
+// prefixes are the UCUM metric and binary prefixes, longest first so that
+// "Ki" is matched before "K".
+var prefixes = []string{
+	"Ki", "Mi", "Gi", "Ti", "da",
+	"Y", "Z", "E", "P", "T", "G", "M", "k", "h",
+	"d", "c", "m", "u", "n", "p", "f", "a", "z", "y",
+}
+
+// ValidateUnit reports whether u is a valid UCUM unit expression using the
+// subset of UCUM appropriate for telemetry. Units may be products (".") and
+// quotients ("/") of prefixed atoms with optional integer exponents, and may
+// be annotated with curly braces, e.g. "{requests}/s" or "KiBy".
+// The empty string is treated as dimensionless.
+func ValidateUnit(u string) error {
+	if u == "" {
+		return nil
+	}
+	for i, term := range strings.Split(u, "/") {
+		if term == "" {
+			if i == 0 {
+				// A leading "/" is an abbreviation for "1/".
+				continue
+			}
+			return fmt.Errorf("invalid unit %q: empty term", u)
+		}
+		for _, factor := range strings.Split(term, ".") {
+			if err := validateFactor(factor); err != nil {
+				return fmt.Errorf("invalid unit %q: %w", u, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateFactor(f string) error {
+	if f == "" {
+		return fmt.Errorf("empty factor")
+	}
+	// Strip a trailing annotation, e.g. "By{compressed}" or "{requests}".
+	if i := strings.IndexByte(f, '{'); i >= 0 {
+		if !strings.HasSuffix(f, "}") || strings.ContainsAny(f[i+1:len(f)-1], "{}") {
+			return fmt.Errorf("malformed annotation in %q", f)
+		}
+		f = f[:i]
+		if f == "" {
+			return nil
+		}
+	}
+	// Plain integers such as "1" are valid dimensionless factors.
+	if strings.Trim(f, "0123456789") == "" {
+		return nil
+	}
+	// Strip an integer exponent, e.g. "m2" or "s-1".
+	f = strings.TrimRight(f, "0123456789")
+	f = strings.TrimSuffix(f, "-")
+	f = strings.TrimSuffix(f, "+")
+	if f == "" {
+		return fmt.Errorf("missing unit atom")
+	}
+	if metricAtoms[f] || nonMetricAtoms[f] {
+		return nil
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(f, p) && metricAtoms[f[len(p):]] {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown unit %q", f)
+}
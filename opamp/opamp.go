@@ -0,0 +1,395 @@
+// Package opamp provides a launcher.RemoteConfigSource which receives
+// remote configuration from an OpAMP server, using the plain HTTP transport
+// of the Open Agent Management Protocol.
+//
+// The source reports itself as an agent which accepts remote configuration
+// and reports its status. The configuration is read from a file in the
+// server's config map, encoded as JSON in the format parsed by
+// launcher.ParseRemoteConfig, and whether it was applied is reported to the
+// server on the next poll.
+//
+//	source := opamp.New("https://opamp.internal/v1/opamp", opamp.WithServiceName("api"))
+//	launcher.ConfigureOpentelemetry(
+//		launcher.WithServiceName("api"),
+//		launcher.WithRemoteConfig(source, launcher.RemoteConfig{}),
+//	)
+package opamp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/common-fate/observability/launcher"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	// DefaultPollingInterval is how often the source polls the server when
+	// WithPollingInterval is not given.
+	DefaultPollingInterval = 30 * time.Second
+
+	// DefaultConfigFile is the name of the file in the server's config map
+	// which holds the configuration, when WithConfigFile is not given.
+	DefaultConfigFile = "observability.json"
+)
+
+// Agent capabilities, as defined by the OpAMP specification.
+const (
+	capabilityReportsStatus       = 0x1
+	capabilityAcceptsRemoteConfig = 0x2
+	capabilityReportsRemoteConfig = 0x1000
+)
+
+// Remote config statuses, as defined by the OpAMP specification.
+const (
+	remoteConfigApplied = 1
+	remoteConfigFailed  = 3
+)
+
+// Source polls an OpAMP server for remote configuration.
+type Source struct {
+	endpoint   string
+	client     *http.Client
+	headers    map[string]string
+	interval   time.Duration
+	configFile string
+	attributes map[string]string
+}
+
+var _ launcher.RemoteConfigSource = &Source{}
+
+// Option configures a Source.
+type Option func(*Source)
+
+// WithHeaders sets headers sent with every request, such as for
+// authentication.
+func WithHeaders(headers map[string]string) Option {
+	return func(s *Source) {
+		s.headers = headers
+	}
+}
+
+// WithHTTPClient sets the client used to reach the server.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Source) {
+		s.client = client
+	}
+}
+
+// WithPollingInterval sets how often the server is polled.
+func WithPollingInterval(interval time.Duration) Option {
+	return func(s *Source) {
+		s.interval = interval
+	}
+}
+
+// WithConfigFile sets the name of the file in the server's config map
+// which holds the configuration. If the config map has a single file, it
+// is used whatever its name.
+func WithConfigFile(name string) Option {
+	return func(s *Source) {
+		s.configFile = name
+	}
+}
+
+// WithServiceName identifies the agent to the server by service name.
+func WithServiceName(name string) Option {
+	return func(s *Source) {
+		s.attributes["service.name"] = name
+	}
+}
+
+// WithServiceVersion describes the version of the service to the server.
+func WithServiceVersion(version string) Option {
+	return func(s *Source) {
+		s.attributes["service.version"] = version
+	}
+}
+
+// New returns a source which polls the OpAMP server at endpoint, such as
+// "https://opamp.internal/v1/opamp".
+func New(endpoint string, opts ...Option) *Source {
+	s := &Source{
+		endpoint:   endpoint,
+		client:     http.DefaultClient,
+		interval:   DefaultPollingInterval,
+		configFile: DefaultConfigFile,
+		attributes: make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Subscribe polls the server until ctx is done or a request fails. Each
+// configuration received is passed to update, unless it is unchanged.
+func (s *Source) Subscribe(ctx context.Context, update func(launcher.RemoteConfig)) error {
+	uid := make([]byte, 16)
+	if _, err := rand.Read(uid); err != nil {
+		return err
+	}
+	var (
+		seq    uint64
+		status remoteConfigStatus
+	)
+	for {
+		msg := agentToServer{
+			instanceUID:  uid,
+			sequenceNum:  seq,
+			attributes:   s.attributes,
+			capabilities: capabilityReportsStatus | capabilityAcceptsRemoteConfig | capabilityReportsRemoteConfig,
+			status:       status,
+		}
+		resp, err := s.poll(ctx, msg)
+		if err != nil {
+			return err
+		}
+		seq++
+		if resp.errorMessage != "" {
+			return fmt.Errorf("opamp server error: %s", resp.errorMessage)
+		}
+		if resp.remoteConfig != nil && !bytes.Equal(resp.remoteConfig.hash, status.hash) {
+			status = remoteConfigStatus{hash: resp.remoteConfig.hash, status: remoteConfigApplied}
+			rc, err := s.parse(resp.remoteConfig)
+			if err != nil {
+				status.status = remoteConfigFailed
+				status.errorMessage = err.Error()
+			} else {
+				update(rc)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.interval):
+		}
+	}
+}
+
+// parse reads the launcher configuration from the config map.
+func (s *Source) parse(rc *agentRemoteConfig) (launcher.RemoteConfig, error) {
+	body, ok := rc.files[s.configFile]
+	if !ok && len(rc.files) == 1 {
+		for _, b := range rc.files {
+			body, ok = b, true
+		}
+	}
+	if !ok {
+		return launcher.RemoteConfig{}, fmt.Errorf("config map has no file %q", s.configFile)
+	}
+	return launcher.ParseRemoteConfig(body)
+}
+
+func (s *Source) poll(ctx context.Context, msg agentToServer) (serverToAgent, error) {
+	req, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(msg.marshal()))
+	if err != nil {
+		return serverToAgent{}, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return serverToAgent{}, err
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, 4<<20))
+	if err != nil {
+		return serverToAgent{}, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return serverToAgent{}, fmt.Errorf("opamp server returned %s", res.Status)
+	}
+	return unmarshalServerToAgent(body)
+}
+
+// The messages below are the subset of the OpAMP protocol used by the
+// source, encoded by hand as the OpAMP Go module requires a newer Go
+// version than this module.
+
+type remoteConfigStatus struct {
+	hash         []byte
+	status       uint64
+	errorMessage string
+}
+
+type agentToServer struct {
+	instanceUID  []byte
+	sequenceNum  uint64
+	attributes   map[string]string
+	capabilities uint64
+	status       remoteConfigStatus
+}
+
+func (m agentToServer) marshal() []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendBytes(b, m.instanceUID)
+	b = protowire.AppendTag(b, 2, protowire.VarintType)
+	b = protowire.AppendVarint(b, m.sequenceNum)
+
+	// AgentDescription.identifying_attributes
+	var desc []byte
+	for k, v := range m.attributes {
+		var value []byte
+		value = protowire.AppendTag(value, 1, protowire.BytesType)
+		value = protowire.AppendString(value, v)
+		var kv []byte
+		kv = protowire.AppendTag(kv, 1, protowire.BytesType)
+		kv = protowire.AppendString(kv, k)
+		kv = protowire.AppendTag(kv, 2, protowire.BytesType)
+		kv = protowire.AppendBytes(kv, value)
+		desc = protowire.AppendTag(desc, 1, protowire.BytesType)
+		desc = protowire.AppendBytes(desc, kv)
+	}
+	b = protowire.AppendTag(b, 3, protowire.BytesType)
+	b = protowire.AppendBytes(b, desc)
+
+	b = protowire.AppendTag(b, 4, protowire.VarintType)
+	b = protowire.AppendVarint(b, m.capabilities)
+
+	if m.status.hash != nil {
+		var st []byte
+		st = protowire.AppendTag(st, 1, protowire.BytesType)
+		st = protowire.AppendBytes(st, m.status.hash)
+		st = protowire.AppendTag(st, 2, protowire.VarintType)
+		st = protowire.AppendVarint(st, m.status.status)
+		if m.status.errorMessage != "" {
+			st = protowire.AppendTag(st, 3, protowire.BytesType)
+			st = protowire.AppendString(st, m.status.errorMessage)
+		}
+		b = protowire.AppendTag(b, 7, protowire.BytesType)
+		b = protowire.AppendBytes(b, st)
+	}
+	return b
+}
+
+type agentRemoteConfig struct {
+	files map[string][]byte
+	hash  []byte
+}
+
+type serverToAgent struct {
+	errorMessage string
+	remoteConfig *agentRemoteConfig
+}
+
+var errInvalidMessage = errors.New("invalid opamp message")
+
+// field is a decoded protobuf field. Length-delimited fields set bytes and
+// varint fields set varint; other fields are skipped.
+type field struct {
+	num    protowire.Number
+	typ    protowire.Type
+	bytes  []byte
+	varint uint64
+}
+
+// fields calls fn with each field of the message b.
+func fields(b []byte, fn func(f field) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return errInvalidMessage
+		}
+		b = b[n:]
+		f := field{num: num, typ: typ}
+		switch typ {
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(b)
+		case protowire.VarintType:
+			f.varint, n = protowire.ConsumeVarint(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return errInvalidMessage
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+func unmarshalServerToAgent(b []byte) (serverToAgent, error) {
+	var m serverToAgent
+	err := fields(b, func(f field) error {
+		if f.typ != protowire.BytesType {
+			return nil
+		}
+		switch f.num {
+		case 2:
+			// ServerErrorResponse.error_message
+			m.errorMessage = "unknown error"
+			return fields(f.bytes, func(f field) error {
+				if f.num == 2 && f.typ == protowire.BytesType {
+					m.errorMessage = string(f.bytes)
+				}
+				return nil
+			})
+		case 3:
+			rc, err := unmarshalAgentRemoteConfig(f.bytes)
+			m.remoteConfig = &rc
+			return err
+		}
+		return nil
+	})
+	return m, err
+}
+
+func unmarshalAgentRemoteConfig(b []byte) (agentRemoteConfig, error) {
+	rc := agentRemoteConfig{files: make(map[string][]byte)}
+	err := fields(b, func(f field) error {
+		if f.typ != protowire.BytesType {
+			return nil
+		}
+		switch f.num {
+		case 1:
+			// AgentConfigMap.config_map entries.
+			return fields(f.bytes, func(entry field) error {
+				if entry.num != 1 || entry.typ != protowire.BytesType {
+					return nil
+				}
+				var name string
+				var body []byte
+				err := fields(entry.bytes, func(f field) error {
+					if f.typ != protowire.BytesType {
+						return nil
+					}
+					switch f.num {
+					case 1:
+						name = string(f.bytes)
+					case 2:
+						// AgentConfigFile.body
+						return fields(f.bytes, func(f field) error {
+							if f.num == 1 && f.typ == protowire.BytesType {
+								body = f.bytes
+							}
+							return nil
+						})
+					}
+					return nil
+				})
+				rc.files[name] = body
+				return err
+			})
+		case 2:
+			rc.hash = f.bytes
+		}
+		return nil
+	})
+	return rc, err
+}
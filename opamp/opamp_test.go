@@ -0,0 +1,190 @@
+package opamp
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/common-fate/observability/launcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// testServer is an OpAMP server which offers a single config file and
+// records the messages it receives.
+type testServer struct {
+	file, body string
+	hash       []byte
+
+	mu       sync.Mutex
+	received []agentToServer
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	msg, err := unmarshalAgentToServer(b)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.received = append(s.received, msg)
+	s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	_, _ = w.Write(s.response())
+}
+
+func (s *testServer) messages() []agentToServer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]agentToServer(nil), s.received...)
+}
+
+// response encodes a ServerToAgent message offering the config file.
+func (s *testServer) response() []byte {
+	var file []byte
+	file = protowire.AppendTag(file, 1, protowire.BytesType)
+	file = protowire.AppendString(file, s.body)
+	var entry []byte
+	entry = protowire.AppendTag(entry, 1, protowire.BytesType)
+	entry = protowire.AppendString(entry, s.file)
+	entry = protowire.AppendTag(entry, 2, protowire.BytesType)
+	entry = protowire.AppendBytes(entry, file)
+	var configMap []byte
+	configMap = protowire.AppendTag(configMap, 1, protowire.BytesType)
+	configMap = protowire.AppendBytes(configMap, entry)
+	var rc []byte
+	rc = protowire.AppendTag(rc, 1, protowire.BytesType)
+	rc = protowire.AppendBytes(rc, configMap)
+	rc = protowire.AppendTag(rc, 2, protowire.BytesType)
+	rc = protowire.AppendBytes(rc, s.hash)
+	var b []byte
+	b = protowire.AppendTag(b, 3, protowire.BytesType)
+	b = protowire.AppendBytes(b, rc)
+	return b
+}
+
+func unmarshalAgentToServer(b []byte) (agentToServer, error) {
+	m := agentToServer{attributes: make(map[string]string)}
+	err := fields(b, func(f field) error {
+		switch f.num {
+		case 1:
+			m.instanceUID = f.bytes
+		case 2:
+			m.sequenceNum = f.varint
+		case 3:
+			return fields(f.bytes, func(kv field) error {
+				var key, value string
+				err := fields(kv.bytes, func(f field) error {
+					switch f.num {
+					case 1:
+						key = string(f.bytes)
+					case 2:
+						return fields(f.bytes, func(f field) error {
+							value = string(f.bytes)
+							return nil
+						})
+					}
+					return nil
+				})
+				m.attributes[key] = value
+				return err
+			})
+		case 4:
+			m.capabilities = f.varint
+		case 7:
+			return fields(f.bytes, func(f field) error {
+				switch f.num {
+				case 1:
+					m.status.hash = f.bytes
+				case 2:
+					m.status.status = f.varint
+				case 3:
+					m.status.errorMessage = string(f.bytes)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return m, err
+}
+
+func TestSource(t *testing.T) {
+	srv := &testServer{file: "observability.json", body: `{"sampling_ratio": 0.25, "dropped_attributes": ["user.email"]}`, hash: []byte{1}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	source := New(ts.URL, WithServiceName("api"), WithPollingInterval(time.Millisecond))
+
+	updates := make(chan launcher.RemoteConfig, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- source.Subscribe(ctx, func(rc launcher.RemoteConfig) { updates <- rc })
+	}()
+
+	select {
+	case rc := <-updates:
+		require.NotNil(t, rc.SamplingRatio)
+		assert.Equal(t, 0.25, *rc.SamplingRatio)
+		assert.Equal(t, []string{"user.email"}, rc.DroppedAttributes)
+	case <-ctx.Done():
+		t.Fatal("no configuration received")
+	}
+	require.Eventually(t, func() bool { return len(srv.messages()) >= 3 }, 5*time.Second, time.Millisecond)
+	cancel()
+	<-done
+
+	// The configuration is applied once, as its hash doesn't change.
+	assert.Len(t, updates, 0)
+	msgs := srv.messages()
+	assert.Equal(t, "api", msgs[0].attributes["service.name"])
+	assert.Equal(t, uint64(0), msgs[0].sequenceNum)
+	assert.Equal(t, uint64(capabilityReportsStatus|capabilityAcceptsRemoteConfig|capabilityReportsRemoteConfig), msgs[0].capabilities)
+	assert.Nil(t, msgs[0].status.hash)
+	assert.Equal(t, uint64(1), msgs[1].sequenceNum)
+	assert.Equal(t, remoteConfigStatus{hash: []byte{1}, status: remoteConfigApplied}, msgs[1].status)
+}
+
+func TestSourceInvalidConfig(t *testing.T) {
+	srv := &testServer{file: "other.json", body: `{"sampling_ratio": 2}`, hash: []byte{2}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	source := New(ts.URL, WithPollingInterval(time.Millisecond))
+	done := make(chan error, 1)
+	go func() {
+		done <- source.Subscribe(ctx, func(launcher.RemoteConfig) { t.Error("invalid configuration applied") })
+	}()
+	require.Eventually(t, func() bool { return len(srv.messages()) >= 2 }, 5*time.Second, time.Millisecond)
+	cancel()
+	<-done
+
+	status := srv.messages()[1].status
+	assert.Equal(t, []byte{2}, status.hash)
+	assert.Equal(t, uint64(remoteConfigFailed), status.status)
+	assert.Equal(t, "invalid remote configuration: sampling ratio 2 is not between 0 and 1", status.errorMessage)
+}
+
+func TestSourceServerError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	err := New(ts.URL).Subscribe(context.Background(), func(launcher.RemoteConfig) {})
+	assert.EqualError(t, err, "opamp server returned 503 Service Unavailable")
+}
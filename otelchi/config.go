@@ -1,6 +1,7 @@
 package otelchi
 
 import (
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
 	oteltrace "go.opentelemetry.io/otel/trace"
 )
@@ -9,6 +10,23 @@ import (
 type config struct {
 	TracerProvider oteltrace.TracerProvider
 	Propagators    propagation.TextMapPropagator
+	// HeaderToBaggage maps request header names to baggage keys.
+	HeaderToBaggage map[string]string
+	// TraceResponseHeaders enables the X-Trace-ID and X-Trace-Sampled
+	// response headers.
+	TraceResponseHeaders bool
+	MeterProvider        metric.MeterProvider
+	// PropagationMetrics enables counting requests by whether they carry
+	// a remote trace context.
+	PropagationMetrics bool
+	// PeerServiceHeader is the request header identifying the calling
+	// service in the propagation metrics.
+	PeerServiceHeader string
+	// PeerServices lists the calling services recorded in the propagation
+	// metrics. If it is nil, the first MaxPeerServices distinct services
+	// are recorded, or DefaultMaxPeerServices if it is zero.
+	PeerServices    []string
+	MaxPeerServices int
 }
 
 // Option specifies instrumentation configuration options.
@@ -38,3 +56,67 @@ func WithTracerProvider(provider oteltrace.TracerProvider) Option {
 		cfg.TracerProvider = provider
 	})
 }
+
+// WithHeaderToBaggage copies the value of each named request header into
+// the request baggage under the mapped key, and records it as an attribute
+// on the server span. For example, map[string]string{"X-Org-ID": "org.id"}
+// captures a legacy correlation header without any per-service code.
+func WithHeaderToBaggage(headers map[string]string) Option {
+	return optionFunc(func(cfg *config) {
+		if cfg.HeaderToBaggage == nil {
+			cfg.HeaderToBaggage = make(map[string]string)
+		}
+		for k, v := range headers {
+			cfg.HeaderToBaggage[k] = v
+		}
+	})
+}
+
+// WithTraceResponseHeaders sets the X-Trace-ID and X-Trace-Sampled
+// response headers to the trace ID and sampling decision of the server
+// span, so that frontend engineers and customers can quote the trace ID
+// when reporting a problem. The headers are set before the handler is
+// called, so handlers may remove them.
+func WithTraceResponseHeaders() Option {
+	return optionFunc(func(cfg *config) {
+		cfg.TraceResponseHeaders = true
+	})
+}
+
+// WithMeterProvider specifies a meter provider to use for creating the
+// propagation metrics. If none is specified, the global provider is used.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.MeterProvider = provider
+	})
+}
+
+// WithPropagationMetrics counts incoming requests in the
+// http.server.trace_context counter, by whether they carry a remote trace
+// context, the propagation format it was sent in, and the calling service,
+// as identified by the value of peerServiceHeader. This measures the
+// adoption of trace propagation by the services calling this one.
+// peerServiceHeader may be empty if callers do not identify themselves.
+//
+// As the header is set by the client, the number of services recorded is
+// bounded: only the first DefaultMaxPeerServices distinct values are
+// recorded, unless WithPeerServices lists the expected services, and any
+// other value is recorded as "other".
+func WithPropagationMetrics(peerServiceHeader string) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.PropagationMetrics = true
+		cfg.PeerServiceHeader = peerServiceHeader
+	})
+}
+
+// DefaultMaxPeerServices is the number of distinct calling services
+// recorded in the propagation metrics when WithPeerServices is not given.
+const DefaultMaxPeerServices = 100
+
+// WithPeerServices lists the calling services recorded in the propagation
+// metrics. Requests from any other service are recorded as "other".
+func WithPeerServices(services ...string) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.PeerServices = append(cfg.PeerServices, services...)
+	})
+}
@@ -1,7 +1,10 @@
 package otelchi
 
 import (
+	"context"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -9,6 +12,9 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/metric/global"
 	"go.opentelemetry.io/otel/propagation"
 
 	otelcontrib "go.opentelemetry.io/contrib"
@@ -18,6 +24,14 @@ import (
 
 const (
 	tracerName = "github.com/riandyrn/otelchi"
+
+	// TraceIDHeader is the response header set to the trace ID by
+	// WithTraceResponseHeaders.
+	TraceIDHeader = "X-Trace-ID"
+	// TraceSampledHeader is the response header set to "true" or "false"
+	// by WithTraceResponseHeaders, depending on whether the trace is
+	// sampled.
+	TraceSampledHeader = "X-Trace-Sampled"
 )
 
 // Middleware sets up a handler to start tracing the incoming
@@ -38,21 +52,34 @@ func Middleware(serverName string, opts ...Option) func(next http.Handler) http.
 	if cfg.Propagators == nil {
 		cfg.Propagators = otel.GetTextMapPropagator()
 	}
+	var pm *propagationMetrics
+	if cfg.PropagationMetrics {
+		if cfg.MeterProvider == nil {
+			cfg.MeterProvider = global.GetMeterProvider()
+		}
+		pm = newPropagationMetrics(cfg.MeterProvider, cfg)
+	}
 	return func(handler http.Handler) http.Handler {
 		return traceware{
-			serverName:  serverName,
-			tracer:      tracer,
-			propagators: cfg.Propagators,
-			handler:     handler,
+			serverName:      serverName,
+			tracer:          tracer,
+			propagators:     cfg.Propagators,
+			headerToBaggage: cfg.HeaderToBaggage,
+			traceHeaders:    cfg.TraceResponseHeaders,
+			propagation:     pm,
+			handler:         handler,
 		}
 	}
 }
 
 type traceware struct {
-	serverName  string
-	tracer      oteltrace.Tracer
-	propagators propagation.TextMapPropagator
-	handler     http.Handler
+	serverName      string
+	tracer          oteltrace.Tracer
+	propagators     propagation.TextMapPropagator
+	headerToBaggage map[string]string
+	traceHeaders    bool
+	propagation     *propagationMetrics
+	handler         http.Handler
 }
 
 type recordingResponseWriter struct {
@@ -103,9 +130,18 @@ func putRRW(rrw *recordingResponseWriter) {
 // tracing of the request.
 func (tw traceware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := tw.propagators.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
-	ctx, span := tw.tracer.Start(ctx, "", oteltrace.WithSpanKind(oteltrace.SpanKindServer))
+	if tw.propagation != nil {
+		tw.propagation.record(ctx, r.Header)
+	}
+	ctx, headerAttrs := tw.captureHeaders(ctx, r.Header)
+	ctx, span := tw.tracer.Start(ctx, "", oteltrace.WithSpanKind(oteltrace.SpanKindServer), oteltrace.WithAttributes(headerAttrs...))
 	defer span.End()
 
+	if sc := span.SpanContext(); tw.traceHeaders && sc.IsValid() {
+		w.Header().Set(TraceIDHeader, sc.TraceID().String())
+		w.Header().Set(TraceSampledHeader, strconv.FormatBool(sc.IsSampled()))
+	}
+
 	r2 := r.WithContext(ctx)
 	rrw := getRRW(w)
 	defer putRRW(rrw)
@@ -124,3 +160,29 @@ func (tw traceware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	spanStatus, spanMessage := semconv.SpanStatusFromHTTPStatusCode(rrw.status)
 	span.SetStatus(spanStatus, spanMessage)
 }
+
+// captureHeaders adds the configured request headers to the baggage in ctx
+// and returns them as span attributes.
+func (tw traceware) captureHeaders(ctx context.Context, header http.Header) (context.Context, []attribute.KeyValue) {
+	if len(tw.headerToBaggage) == 0 {
+		return ctx, nil
+	}
+	bag := baggage.FromContext(ctx)
+	var attrs []attribute.KeyValue
+	for name, key := range tw.headerToBaggage {
+		value := header.Get(name)
+		if value == "" {
+			continue
+		}
+		attrs = append(attrs, attribute.String(key, value))
+		m, err := baggage.NewMember(key, url.QueryEscape(value))
+		if err != nil {
+			otel.Handle(err)
+			continue
+		}
+		if bag, err = bag.SetMember(m); err != nil {
+			otel.Handle(err)
+		}
+	}
+	return baggage.ContextWithBaggage(ctx, bag), attrs
+}
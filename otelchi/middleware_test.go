@@ -14,6 +14,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/metric/metrictest"
 	"go.opentelemetry.io/otel/propagation"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
@@ -193,6 +195,57 @@ func TestSDKIntegration(t *testing.T) {
 	)
 }
 
+func TestHeaderToBaggage(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider()
+	provider.RegisterSpanProcessor(sr)
+
+	var called bool
+	router := chi.NewRouter()
+	router.Use(Middleware("foobar",
+		WithTracerProvider(provider),
+		WithHeaderToBaggage(map[string]string{"X-Org-ID": "org.id"}),
+	))
+	router.HandleFunc("/user/{id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		assert.Equal(t, "acme", baggage.FromContext(r.Context()).Member("org.id").Value())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	r := httptest.NewRequest("GET", "/user/123", nil)
+	r.Header.Set("X-Org-ID", "acme")
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.True(t, called, "failed to run test")
+	require.Len(t, sr.Ended(), 1)
+	assertSpan(t, sr.Ended()[0],
+		"/user/{id}",
+		trace.SpanKindServer,
+		attribute.String("org.id", "acme"),
+	)
+}
+
+func TestTraceResponseHeaders(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	router := chi.NewRouter()
+	router.Use(Middleware("foobar",
+		WithTracerProvider(provider),
+		WithPropagators(propagation.TraceContext{}),
+		WithTraceResponseHeaders(),
+	))
+	router.HandleFunc("/user/{id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	r := httptest.NewRequest("GET", "/user/123", nil)
+	propagation.TraceContext{}.Inject(trace.ContextWithRemoteSpanContext(context.Background(), sc), propagation.HeaderCarrier(r.Header))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, sc.TraceID().String(), w.Header().Get(TraceIDHeader))
+	assert.Equal(t, "true", w.Header().Get(TraceSampledHeader))
+}
+
 func assertSpan(t *testing.T, span sdktrace.ReadOnlySpan, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) {
 	assert.Equal(t, name, span.Name())
 	assert.Equal(t, trace.SpanKindServer, span.SpanKind())
@@ -208,3 +261,62 @@ func assertSpan(t *testing.T, span sdktrace.ReadOnlySpan, name string, kind trac
 		assert.Equal(t, got[want.Key], want.Value)
 	}
 }
+
+func TestPropagationMetrics(t *testing.T) {
+	provider := metrictest.NewMeterProvider()
+	router := chi.NewRouter()
+	router.Use(Middleware("foobar",
+		WithPropagators(propagation.TraceContext{}),
+		WithMeterProvider(provider),
+		WithPropagationMetrics("X-Service-Name"),
+	))
+	router.HandleFunc("/user/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	r := httptest.NewRequest("GET", "/user/123", nil)
+	r.Header.Set("Traceparent", "00-0102030405060708090a0b0c0d0e0f10-0102030405060708-01")
+	r.Header.Set("X-Service-Name", "billing")
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/user/123", nil))
+
+	measured := metrictest.AsStructs(provider.MeasurementBatches)
+	require.Len(t, measured, 2)
+	assert.Equal(t, "http.server.trace_context", measured[0].Name)
+	assert.Equal(t, metrictest.LabelsToMap(
+		TraceContextRemoteKey.Bool(true),
+		TraceContextFormatKey.String("tracecontext"),
+		attribute.String("peer.service", "billing"),
+	), measured[0].Labels)
+	assert.Equal(t, metrictest.LabelsToMap(
+		TraceContextRemoteKey.Bool(false),
+		TraceContextFormatKey.String("none"),
+		attribute.String("peer.service", "unknown"),
+	), measured[1].Labels)
+}
+
+func TestPropagationMetricsPeerServices(t *testing.T) {
+	peers := func(opts ...Option) []string {
+		provider := metrictest.NewMeterProvider()
+		router := chi.NewRouter()
+		router.Use(Middleware("foobar", append(opts, WithMeterProvider(provider), WithPropagationMetrics("X-Service-Name"))...))
+		router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+		for _, name := range []string{"billing", "audit", "billing", "attacker-1", "attacker-2"} {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("X-Service-Name", name)
+			router.ServeHTTP(httptest.NewRecorder(), r)
+		}
+		var got []string
+		for _, m := range metrictest.AsStructs(provider.MeasurementBatches) {
+			got = append(got, m.Labels["peer.service"].AsString())
+		}
+		return got
+	}
+
+	assert.Equal(t, []string{"billing", "other", "billing", "other", "other"}, peers(WithPeerServices("billing")))
+
+	m := newPropagationMetrics(metrictest.NewMeterProvider(), config{PeerServiceHeader: "X-Service-Name", MaxPeerServices: 2})
+	var got []string
+	for _, name := range []string{"billing", "audit", "billing", "attacker-1", ""} {
+		got = append(got, m.peerService(name))
+	}
+	assert.Equal(t, []string{"billing", "audit", "billing", "other", "unknown"}, got)
+}
@@ -0,0 +1,109 @@
+package otelchi
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Attributes of the http.server.trace_context counter.
+const (
+	TraceContextRemoteKey = attribute.Key("trace_context.remote")
+	TraceContextFormatKey = attribute.Key("trace_context.format")
+)
+
+// propagationFormats lists the headers which identify each propagation
+// format, in the order they are checked.
+var propagationFormats = []struct {
+	header string
+	format string
+}{
+	{"Traceparent", "tracecontext"},
+	{"B3", "b3single"},
+	{"X-B3-Traceid", "b3"},
+	{"Uber-Trace-Id", "jaeger"},
+	{"X-Amzn-Trace-Id", "xray"},
+	{"Ot-Tracer-Traceid", "ottrace"},
+	{"Cf-Trace", "cfbinary"},
+}
+
+// propagationMetrics counts requests by the trace context they carry.
+type propagationMetrics struct {
+	counter           metric.Int64Counter
+	peerServiceHeader string
+	// peerServices are the calling services which are recorded. Unless
+	// they are allowlisted, services are added as they are seen until
+	// there are maxPeerServices.
+	allowlisted     bool
+	maxPeerServices int
+
+	mu           sync.Mutex
+	peerServices map[string]struct{}
+}
+
+func newPropagationMetrics(provider metric.MeterProvider, cfg config) *propagationMetrics {
+	meter := provider.Meter(tracerName)
+	counter, err := meter.NewInt64Counter("http.server.trace_context",
+		metric.WithDescription("Number of requests received, by whether they carry a remote trace context"),
+		metric.WithUnit("{requests}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	m := &propagationMetrics{
+		counter:           counter,
+		peerServiceHeader: cfg.PeerServiceHeader,
+		allowlisted:       cfg.PeerServices != nil,
+		maxPeerServices:   cfg.MaxPeerServices,
+		peerServices:      make(map[string]struct{}),
+	}
+	if m.maxPeerServices == 0 {
+		m.maxPeerServices = DefaultMaxPeerServices
+	}
+	for _, s := range cfg.PeerServices {
+		m.peerServices[s] = struct{}{}
+	}
+	return m
+}
+
+// peerService returns the value recorded for the calling service peer.
+func (m *propagationMetrics) peerService(peer string) string {
+	if peer == "" {
+		return "unknown"
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.peerServices[peer]; ok {
+		return peer
+	}
+	if m.allowlisted || len(m.peerServices) >= m.maxPeerServices {
+		return "other"
+	}
+	m.peerServices[peer] = struct{}{}
+	return peer
+}
+
+// record counts a request, given the context extracted from its headers.
+func (m *propagationMetrics) record(ctx context.Context, header http.Header) {
+	sc := oteltrace.SpanContextFromContext(ctx)
+	remote := sc.IsValid() && sc.IsRemote()
+	format := "none"
+	for _, f := range propagationFormats {
+		if header.Get(f.header) != "" {
+			format = f.format
+			break
+		}
+	}
+	attrs := []attribute.KeyValue{TraceContextRemoteKey.Bool(remote), TraceContextFormatKey.String(format)}
+	if m.peerServiceHeader != "" {
+		peer := m.peerService(header.Get(m.peerServiceHeader))
+		attrs = append(attrs, semconv.PeerServiceKey.String(peer))
+	}
+	m.counter.Add(ctx, 1, attrs...)
+}
@@ -16,6 +16,7 @@ package otelgrpc
 
 import (
 	"context"
+	"net/url"
 
 	"google.golang.org/grpc/metadata"
 
@@ -35,8 +36,9 @@ const (
 
 // config is a group of options for this instrumentation.
 type config struct {
-	Propagators    propagation.TextMapPropagator
-	TracerProvider trace.TracerProvider
+	Propagators     propagation.TextMapPropagator
+	TracerProvider  trace.TracerProvider
+	HeaderToBaggage map[string]string
 }
 
 // Option applies an option value for a config.
@@ -84,6 +86,24 @@ func WithTracerProvider(tp trace.TracerProvider) Option {
 	return tracerProviderOption{tp: tp}
 }
 
+type headerToBaggageOption struct{ headers map[string]string }
+
+func (o headerToBaggageOption) apply(c *config) {
+	if c.HeaderToBaggage == nil {
+		c.HeaderToBaggage = make(map[string]string)
+	}
+	for k, v := range o.headers {
+		c.HeaderToBaggage[k] = v
+	}
+}
+
+// WithHeaderToBaggage returns an Option which copies the value of each named
+// incoming metadata header into the request baggage under the mapped key,
+// and records it as an attribute on the server span.
+func WithHeaderToBaggage(headers map[string]string) Option {
+	return headerToBaggageOption{headers: headers}
+}
+
 type metadataSupplier struct {
 	metadata *metadata.MD
 }
@@ -132,3 +152,29 @@ func Extract(ctx context.Context, metadata *metadata.MD, opts ...Option) (baggag
 
 	return baggage.FromContext(ctx), trace.SpanContextFromContext(ctx)
 }
+
+// headersToBaggage adds the configured metadata headers to the baggage
+// in ctx and returns them as span attributes.
+func headersToBaggage(ctx context.Context, md metadata.MD, headers map[string]string) (context.Context, []attribute.KeyValue) {
+	if len(headers) == 0 {
+		return ctx, nil
+	}
+	bag := baggage.FromContext(ctx)
+	var attrs []attribute.KeyValue
+	for name, key := range headers {
+		values := md.Get(name)
+		if len(values) == 0 || values[0] == "" {
+			continue
+		}
+		attrs = append(attrs, attribute.String(key, values[0]))
+		m, err := baggage.NewMember(key, url.QueryEscape(values[0]))
+		if err != nil {
+			otel.Handle(err)
+			continue
+		}
+		if bag, err = bag.SetMember(m); err != nil {
+			otel.Handle(err)
+		}
+	}
+	return baggage.ContextWithBaggage(ctx, bag), attrs
+}
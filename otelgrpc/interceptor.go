@@ -308,12 +308,15 @@ func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
 		bags, spanCtx := Extract(ctx, &metadataCopy, opts...)
 		ctx = baggage.ContextWithBaggage(ctx, bags)
 
-		tracer := newConfig(opts).TracerProvider.Tracer(
+		cfg := newConfig(opts)
+		tracer := cfg.TracerProvider.Tracer(
 			instrumentationName,
 			trace.WithInstrumentationVersion(SemVersion()),
 		)
 
+		ctx, headerAttr := headersToBaggage(ctx, metadataCopy, cfg.HeaderToBaggage)
 		name, attr := spanInfo(info.FullMethod, peerFromCtx(ctx))
+		attr = append(attr, headerAttr...)
 		ctx, span := tracer.Start(
 			trace.ContextWithRemoteSpanContext(ctx, spanCtx),
 			name,
@@ -397,12 +400,15 @@ func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
 		bags, spanCtx := Extract(ctx, &metadataCopy, opts...)
 		ctx = baggage.ContextWithBaggage(ctx, bags)
 
-		tracer := newConfig(opts).TracerProvider.Tracer(
+		cfg := newConfig(opts)
+		tracer := cfg.TracerProvider.Tracer(
 			instrumentationName,
 			trace.WithInstrumentationVersion(SemVersion()),
 		)
 
+		ctx, headerAttr := headersToBaggage(ctx, metadataCopy, cfg.HeaderToBaggage)
 		name, attr := spanInfo(info.FullMethod, peerFromCtx(ctx))
+		attr = append(attr, headerAttr...)
 		ctx, span := tracer.Start(
 			trace.ContextWithRemoteSpanContext(ctx, spanCtx),
 			name,
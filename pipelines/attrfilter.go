@@ -0,0 +1,97 @@
+package pipelines
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/sdkapi"
+	"go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// AttributeFilter returns the keys of the span attributes to remove before
+// export. It is called for every span, so it must be cheap, such as
+// loading a set which is replaced when the filter changes.
+type AttributeFilter func() map[attribute.Key]struct{}
+
+// attributeFilterProcessor removes the attributes returned by its filter
+// from spans before they are passed to the next processor.
+type attributeFilterProcessor struct {
+	next   trace.SpanProcessor
+	filter AttributeFilter
+}
+
+var _ trace.SpanProcessor = &attributeFilterProcessor{}
+
+func newAttributeFilterProcessor(next trace.SpanProcessor, filter AttributeFilter) *attributeFilterProcessor {
+	return &attributeFilterProcessor{next: next, filter: filter}
+}
+
+func (p *attributeFilterProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p *attributeFilterProcessor) OnEnd(s trace.ReadOnlySpan) {
+	p.next.OnEnd(filterAttributes(s, p.filter()))
+}
+
+func (p *attributeFilterProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *attributeFilterProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// filterAttributes returns s, or a copy of it without the attributes in
+// dropped.
+func filterAttributes(s trace.ReadOnlySpan, dropped map[attribute.Key]struct{}) trace.ReadOnlySpan {
+	if len(dropped) == 0 {
+		return s
+	}
+	attrs := s.Attributes()
+	var out []attribute.KeyValue
+	for i, kv := range attrs {
+		if _, ok := dropped[kv.Key]; !ok {
+			if out != nil {
+				out = append(out, kv)
+			}
+			continue
+		}
+		if out == nil {
+			out = append(make([]attribute.KeyValue, 0, len(attrs)-1), attrs[:i]...)
+		}
+	}
+	if out == nil {
+		return s
+	}
+	return rewrittenSpan{
+		ReadOnlySpan: s,
+		name:         s.Name(),
+		attributes:   out,
+		events:       s.Events(),
+		status:       s.Status(),
+	}
+}
+
+// pausableMetricExporter discards the metrics collected while paused
+// returns true, rather than exporting them.
+type pausableMetricExporter struct {
+	next   metric.Exporter
+	paused func() bool
+}
+
+var _ metric.Exporter = pausableMetricExporter{}
+
+func (e pausableMetricExporter) TemporalityFor(desc *sdkapi.Descriptor, kind aggregation.Kind) aggregation.Temporality {
+	return e.next.TemporalityFor(desc, kind)
+}
+
+func (e pausableMetricExporter) Export(ctx context.Context, res *resource.Resource, reader metric.InstrumentationLibraryReader) error {
+	if e.paused() {
+		return nil
+	}
+	return e.next.Export(ctx, res, reader)
+}
@@ -0,0 +1,115 @@
+package pipelines
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// spanOverheadBytes approximates the encoded size of the fixed fields of a
+// span: its IDs, timestamps, kind and status.
+const spanOverheadBytes = 64
+
+// batchSizeProcessor flushes the next processor, which is expected to be a
+// batch span processor, once the estimated size of the spans queued since
+// the last flush exceeds maxBytes. The batch span processor only flushes by
+// span count and time, so without this a batch of large spans can exceed
+// the collector's maximum message size.
+//
+// Flushes run in the background so that ending a span never blocks on an
+// export. The limit is therefore approximate: spans which end while a flush
+// is in progress may be included in the same export.
+type batchSizeProcessor struct {
+	next     trace.SpanProcessor
+	maxBytes int64
+
+	queued   int64
+	flushing int32
+}
+
+var _ trace.SpanProcessor = &batchSizeProcessor{}
+
+func newBatchSizeProcessor(next trace.SpanProcessor, maxBytes int) *batchSizeProcessor {
+	return &batchSizeProcessor{next: next, maxBytes: int64(maxBytes)}
+}
+
+func (p *batchSizeProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p *batchSizeProcessor) OnEnd(s trace.ReadOnlySpan) {
+	p.next.OnEnd(s)
+	if !s.SpanContext().IsSampled() {
+		return
+	}
+	if atomic.AddInt64(&p.queued, int64(estimateSpanSize(s))) < p.maxBytes {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&p.flushing, 0, 1) {
+		return
+	}
+	atomic.StoreInt64(&p.queued, 0)
+	go func() {
+		defer atomic.StoreInt32(&p.flushing, 0)
+		if err := p.next.ForceFlush(context.Background()); err != nil {
+			otel.Handle(err)
+		}
+	}()
+}
+
+func (p *batchSizeProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *batchSizeProcessor) ForceFlush(ctx context.Context) error {
+	atomic.StoreInt64(&p.queued, 0)
+	return p.next.ForceFlush(ctx)
+}
+
+// estimateSpanSize approximates the size of s once encoded for export. It
+// counts the variable length fields, which dominate the size of large
+// spans, and ignores the encoding overhead of each field.
+func estimateSpanSize(s trace.ReadOnlySpan) int {
+	n := spanOverheadBytes + len(s.Name())
+	n += attributesSize(s.Attributes())
+	for _, e := range s.Events() {
+		n += spanOverheadBytes/4 + len(e.Name) + attributesSize(e.Attributes)
+	}
+	for _, l := range s.Links() {
+		n += spanOverheadBytes/2 + attributesSize(l.Attributes)
+	}
+	n += len(s.Status().Description)
+	return n
+}
+
+func attributesSize(attrs []attribute.KeyValue) int {
+	var n int
+	for _, kv := range attrs {
+		n += len(kv.Key) + attributeValueSize(kv.Value)
+	}
+	return n
+}
+
+func attributeValueSize(v attribute.Value) int {
+	switch v.Type() {
+	case attribute.STRING:
+		return len(v.AsString())
+	case attribute.STRINGSLICE:
+		var n int
+		for _, s := range v.AsStringSlice() {
+			n += len(s)
+		}
+		return n
+	case attribute.BOOLSLICE:
+		return len(v.AsBoolSlice())
+	case attribute.INT64SLICE:
+		return 8 * len(v.AsInt64Slice())
+	case attribute.FLOAT64SLICE:
+		return 8 * len(v.AsFloat64Slice())
+	default:
+		return 8
+	}
+}
@@ -0,0 +1,47 @@
+package pipelines
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type flushRecorder struct {
+	*tracetest.SpanRecorder
+	flushed chan struct{}
+}
+
+func (r flushRecorder) ForceFlush(ctx context.Context) error {
+	r.flushed <- struct{}{}
+	return nil
+}
+
+func TestBatchSizeProcessor(t *testing.T) {
+	next := flushRecorder{SpanRecorder: tracetest.NewSpanRecorder(), flushed: make(chan struct{}, 1)}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(newBatchSizeProcessor(next, 1000)))
+	tracer := tp.Tracer("test")
+
+	_, span := tracer.Start(context.Background(), "small")
+	span.End()
+	select {
+	case <-next.flushed:
+		t.Fatal("flushed before the size limit was reached")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	_, span = tracer.Start(context.Background(), "large", trace.WithAttributes(attribute.String("payload", strings.Repeat("x", 1000))))
+	span.End()
+	select {
+	case <-next.flushed:
+	case <-time.After(time.Second):
+		t.Fatal("not flushed after the size limit was exceeded")
+	}
+	assert.Len(t, next.Ended(), 2)
+}
@@ -0,0 +1,121 @@
+package pipelines
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric/sdkapi"
+	"go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.uber.org/multierr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// bisectingExporter retries metric exports which the collector rejects as
+// invalid by splitting the batch in half, recursively, until the records
+// which cause the rejection are isolated. Those records are dropped and
+// reported to the OpenTelemetry error handler, and the rest of the batch
+// is exported. Without this, a single bad instrument causes every metric in
+// the collection period to be lost.
+//
+// Other failures, such as an unavailable collector, affect every batch
+// equally and are returned without retrying.
+type bisectingExporter struct {
+	next metric.Exporter
+}
+
+var _ metric.Exporter = bisectingExporter{}
+
+func (e bisectingExporter) TemporalityFor(desc *sdkapi.Descriptor, kind aggregation.Kind) aggregation.Temporality {
+	return e.next.TemporalityFor(desc, kind)
+}
+
+func (e bisectingExporter) Export(ctx context.Context, res *resource.Resource, reader metric.InstrumentationLibraryReader) error {
+	var records recordsReader
+	err := reader.ForEach(func(lib instrumentation.Library, r metric.Reader) error {
+		return r.ForEach(e.next, func(rec metric.Record) error {
+			records = append(records, libraryRecord{lib: lib, record: rec})
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return e.export(ctx, res, records)
+}
+
+func (e bisectingExporter) export(ctx context.Context, res *resource.Resource, records recordsReader) error {
+	err := e.next.Export(ctx, res, records)
+	if err == nil || !isInvalidArgument(err) {
+		return err
+	}
+	if len(records) == 1 {
+		otel.Handle(DroppedError{fmt.Errorf("dropping metric %s rejected by the collector: %w", records[0].record.Descriptor().Name(), err)})
+		return nil
+	}
+	mid := len(records) / 2
+	err = e.export(ctx, res, records[:mid])
+	if ctx.Err() != nil {
+		return err
+	}
+	return multierr.Append(err, e.export(ctx, res, records[mid:]))
+}
+
+// isInvalidArgument reports whether the collector rejected the export
+// because of its contents.
+func isInvalidArgument(err error) bool {
+	var se interface{ GRPCStatus() *status.Status }
+	return errors.As(err, &se) && se.GRPCStatus().Code() == codes.InvalidArgument
+}
+
+type libraryRecord struct {
+	lib    instrumentation.Library
+	record metric.Record
+}
+
+// recordsReader replays records which have already been read from a
+// checkpoint. The records were read using the temporality of the exporter,
+// so the selector passed to ForEach is ignored.
+type recordsReader []libraryRecord
+
+var _ metric.InstrumentationLibraryReader = recordsReader{}
+
+func (r recordsReader) ForEach(readerFunc func(instrumentation.Library, metric.Reader) error) error {
+	for start := 0; start < len(r); {
+		end := start + 1
+		for end < len(r) && r[end].lib == r[start].lib {
+			end++
+		}
+		if err := readerFunc(r[start].lib, libraryReader(r[start:end])); err != nil {
+			return err
+		}
+		start = end
+	}
+	return nil
+}
+
+// libraryReader replays the records of a single instrumentation library.
+type libraryReader []libraryRecord
+
+var _ metric.Reader = libraryReader{}
+
+func (r libraryReader) ForEach(_ aggregation.TemporalitySelector, recordFunc func(metric.Record) error) error {
+	for _, lr := range r {
+		if err := recordFunc(lr.record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The records are only read, and the checkpoint they were read from is
+// locked by the controller for the duration of the export.
+func (libraryReader) Lock()    {}
+func (libraryReader) Unlock()  {}
+func (libraryReader) RLock()   {}
+func (libraryReader) RUnlock() {}
@@ -0,0 +1,74 @@
+package pipelines
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/number"
+	"go.opentelemetry.io/otel/metric/sdkapi"
+	"go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// rejectingExporter rejects batches which contain the named metric.
+type rejectingExporter struct {
+	aggregation.TemporalitySelector
+	reject   string
+	exported []string
+}
+
+func (e *rejectingExporter) Export(ctx context.Context, res *resource.Resource, reader metric.InstrumentationLibraryReader) error {
+	var names []string
+	_ = reader.ForEach(func(lib instrumentation.Library, r metric.Reader) error {
+		return r.ForEach(e, func(rec metric.Record) error {
+			names = append(names, lib.Name+"/"+rec.Descriptor().Name())
+			return nil
+		})
+	})
+	for _, n := range names {
+		if n == e.reject {
+			return status.Error(codes.InvalidArgument, "invalid metric")
+		}
+	}
+	e.exported = append(e.exported, names...)
+	return nil
+}
+
+func TestBisectingExporter(t *testing.T) {
+	var records recordsReader
+	for _, r := range []struct{ lib, name string }{{"a", "requests"}, {"a", "bad"}, {"a", "latency"}, {"b", "jobs"}} {
+		desc := sdkapi.NewDescriptor(r.name, sdkapi.CounterInstrumentKind, number.Int64Kind, "", "")
+		labels := attribute.NewSet()
+		records = append(records, libraryRecord{
+			lib:    instrumentation.Library{Name: r.lib},
+			record: metric.NewRecord(&desc, &labels, nil, time.Time{}, time.Time{}),
+		})
+	}
+
+	next := &rejectingExporter{TemporalitySelector: aggregation.CumulativeTemporalitySelector(), reject: "a/bad"}
+	err := bisectingExporter{next: next}.Export(context.Background(), resource.Empty(), records)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a/requests", "a/latency", "b/jobs"}, next.exported)
+
+	unavailable := &unavailableExporter{rejectingExporter: next}
+	err = bisectingExporter{next: unavailable}.Export(context.Background(), resource.Empty(), records)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 1, unavailable.calls)
+}
+
+type unavailableExporter struct {
+	*rejectingExporter
+	calls int
+}
+
+func (e *unavailableExporter) Export(context.Context, *resource.Resource, metric.InstrumentationLibraryReader) error {
+	e.calls++
+	return status.Error(codes.Unavailable, "connection refused")
+}
@@ -0,0 +1,80 @@
+package pipelines
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/export/metric"
+)
+
+// OverflowAttribute marks the data point into which the measurements of
+// attribute sets beyond an instrument's cardinality limit are aggregated.
+const OverflowAttribute = attribute.Key("otel.metric.overflow")
+
+var overflowSet = attribute.NewSet(OverflowAttribute.Bool(true))
+
+// cardinalityCheckpointerFactory limits the number of distinct attribute
+// sets exported for each instrument.
+type cardinalityCheckpointerFactory struct {
+	limit       int
+	instruments map[string]int
+	next        metric.CheckpointerFactory
+}
+
+func (f cardinalityCheckpointerFactory) NewCheckpointer() metric.Checkpointer {
+	return &cardinalityLimiter{
+		Checkpointer: f.next.NewCheckpointer(),
+		limit:        f.limit,
+		instruments:  f.instruments,
+		seen:         make(map[string]map[attribute.Distinct]bool),
+		overflowed:   make(map[string]bool),
+	}
+}
+
+// cardinalityLimiter aggregates the measurements of an instrument with
+// attribute sets beyond the first limit it has seen into a single overflow
+// data point, which carries only OverflowAttribute. Attribute sets are
+// remembered for the lifetime of the process, as they are by cumulative
+// export.
+type cardinalityLimiter struct {
+	metric.Checkpointer
+	limit       int
+	instruments map[string]int
+	seen        map[string]map[attribute.Distinct]bool
+	overflowed  map[string]bool
+}
+
+// limitFor returns the cardinality limit of an instrument, or zero if it
+// is unlimited.
+func (l *cardinalityLimiter) limitFor(name string) int {
+	if limit, ok := l.instruments[name]; ok {
+		return limit
+	}
+	return l.limit
+}
+
+func (l *cardinalityLimiter) Process(accum metric.Accumulation) error {
+	desc := accum.Descriptor()
+	limit := l.limitFor(desc.Name())
+	if limit <= 0 {
+		return l.Checkpointer.Process(accum)
+	}
+	seen, ok := l.seen[desc.Name()]
+	if !ok {
+		seen = make(map[attribute.Distinct]bool)
+		l.seen[desc.Name()] = seen
+	}
+	key := accum.Labels().Equivalent()
+	if !seen[key] {
+		if len(seen) >= limit {
+			if !l.overflowed[desc.Name()] {
+				l.overflowed[desc.Name()] = true
+				otel.Handle(fmt.Errorf("metric %s exceeded its cardinality limit of %d attribute sets: further attribute sets are aggregated into %s", desc.Name(), limit, OverflowAttribute))
+			}
+			return l.Checkpointer.Process(metric.NewAccumulation(desc, &overflowSet, accum.Aggregator()))
+		}
+		seen[key] = true
+	}
+	return l.Checkpointer.Process(accum)
+}
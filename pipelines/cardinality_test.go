@@ -0,0 +1,53 @@
+package pipelines
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+	processor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
+	"go.opentelemetry.io/otel/sdk/metric/processor/processortest"
+	selector "go.opentelemetry.io/otel/sdk/metric/selector/simple"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+func TestCardinalityLimiter(t *testing.T) {
+	ctx := context.Background()
+	exporter := processortest.New(aggregation.CumulativeTemporalitySelector(), attribute.DefaultEncoder())
+	cont := controller.New(
+		cardinalityCheckpointerFactory{
+			limit:       2,
+			instruments: map[string]int{"logins.sum": 0},
+			next:        processor.NewFactory(selector.NewWithInexpensiveDistribution(), exporter, processor.WithMemory(true)),
+		},
+		controller.WithResource(resource.Empty()),
+		controller.WithCollectPeriod(0),
+	)
+
+	meter := otelmetric.Must(cont.Meter("test"))
+	requests := meter.NewInt64Counter("requests.sum")
+	logins := meter.NewInt64Counter("logins.sum")
+	for _, users := range [][]string{{"alice", "bob"}, {"carol", "dave", "alice"}} {
+		for _, user := range users {
+			requests.Add(ctx, 1, attribute.String("user", user))
+			logins.Add(ctx, 1, attribute.String("user", user))
+		}
+		require.NoError(t, cont.Collect(ctx))
+	}
+
+	require.NoError(t, exporter.Export(ctx, resource.Empty(), cont))
+	assert.Equal(t, map[string]float64{
+		"requests.sum/user=alice/":                2,
+		"requests.sum/user=bob/":                  1,
+		"requests.sum/otel.metric.overflow=true/": 2,
+		"logins.sum/user=alice/":                  2,
+		"logins.sum/user=bob/":                    1,
+		"logins.sum/user=carol/":                  1,
+		"logins.sum/user=dave/":                   1,
+	}, exporter.Values())
+}
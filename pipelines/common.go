@@ -1,19 +1,104 @@
 package pipelines
 
 import (
+	"context"
+	"crypto/tls"
+	"regexp"
 	"time"
 
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/keepalive"
 )
 
 type PipelineConfig struct {
-	Endpoint        string
-	Insecure        bool
-	Headers         map[string]string
-	Resource        *resource.Resource
-	ReportingPeriod string
-	BatchTimeout    time.Duration
-	Propagators     []string
+	Endpoint                       string
+	Insecure                       bool
+	TLSConfig                      *tls.Config
+	CACertificateFile              string
+	ClientCertificateFile          string
+	ClientKeyFile                  string
+	Headers                        map[string]string
+	CredentialsRefresher           CredentialsRefresher
+	Resource                       *resource.Resource
+	ReportingPeriod                string
+	MetricTemporality              string
+	MetricViews                    []MetricView
+	HistogramBuckets               map[string][]float64
+	HostMetrics                    bool
+	SelfMetrics                    bool
+	CardinalityLimit               int
+	InstrumentCardinalityLimits    map[string]int
+	LoadSignal                     func() float64
+	LoadThreshold                  float64
+	CriticalInstruments            []string
+	LoadSheddingDownsampleEvery    int
+	BatchTimeout                   time.Duration
+	BatchSpanProcessorOptions      []trace.BatchSpanProcessorOption
+	MaxExportStaleness             time.Duration
+	Propagators                    []string
+	TextMapPropagators             []propagation.TextMapPropagator
+	Sampler                        trace.Sampler
+	BiasedSampling                 bool
+	BiasedSamplingRatio            float64
+	BiasedSamplingLatencyThreshold time.Duration
+	OperationSLAs                  map[string]time.Duration
+	SpanMetrics                    bool
+	SpanCostMetrics                bool
+	ResourceFromSpanAttributes     []string
+	DynamicResourceAttributes      DynamicResourceAttributes
+	SpanStartHooks                 []SpanStartHook
+	SpanEndHooks                   []SpanEndHook
+	SyncExport                     bool
+	MaxExportBatchBytes            int
+	DiskBufferDir                  string
+	DiskBufferMaxBytes             int64
+	SpanLimits                     trace.SpanLimits
+	AttributeValueLengthLimit      int
+	MaxSpanDepth                   int
+	PrioritySpan                   func(trace.ReadOnlySpan) bool
+	ExportInspector                ExportInspector
+	ScrubPatterns                  []*regexp.Regexp
+	AttributeFilter                AttributeFilter
+	MetricsPaused                  func() bool
+	DroppedSpans                   []SpanMatcher
+	ReconnectBackoff               backoff.Config
+	Keepalive                      keepalive.ClientParameters
+	ExportRetry                    *RetryConfig
+	DialOptions                    []grpc.DialOption
+	ProxyURL                       string
+	OnConnectionStateChange        func(connectivity.State)
+}
+
+// RetryConfig configures the exponential backoff with which the exporters
+// retry failed exports. It has the same fields as the retry configuration
+// of the OTLP exporters.
+type RetryConfig struct {
+	Enabled         bool
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	MaxElapsedTime  time.Duration
+}
+
+// Pipeline is a running export pipeline for a single signal.
+type Pipeline struct {
+	// Shutdown flushes any pending telemetry and stops the pipeline.
+	Shutdown func(context.Context) error
+	// ForceFlush exports any pending telemetry without stopping the pipeline.
+	ForceFlush func(context.Context) error
+	// ForwardTraces exports spans encoded as OTLP through the pipeline. It
+	// is only set for trace pipelines.
+	ForwardTraces func(context.Context, *tracepb.ResourceSpans) error
+	// Stats returns the number of items the pipeline has exported.
+	Stats func() ExportStats
+	// Update changes the endpoint and headers of the exporter without
+	// restarting the pipeline.
+	Update func(context.Context, ExporterUpdate) error
 }
 
 type PipelineSetupFunc func(PipelineConfig) (func() error, error)
@@ -0,0 +1,93 @@
+package pipelines
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+)
+
+// minConnectTimeout matches the gRPC default, which is otherwise reset to
+// zero when custom connect params are supplied.
+const minConnectTimeout = 20 * time.Second
+
+// dialExporter creates the gRPC channel used by an OTLP exporter.
+// The channel reconnects with jittered exponential backoff and reports
+// its state transitions to c.OnConnectionStateChange, if set. If
+// c.CredentialsRefresher is set, calls rejected for their credentials are
+// retried once with refreshed headers. If c.ProxyURL is set, the channel
+// connects through the proxy; otherwise gRPC uses the proxy configured by
+// the HTTPS_PROXY and NO_PROXY environment variables. c.DialOptions are applied last, so
+// they take precedence over the defaults.
+func dialExporter(ctx context.Context, c PipelineConfig) (*grpc.ClientConn, error) {
+	secureOption, err := transportCredentials(c)
+	if err != nil {
+		return nil, err
+	}
+	reconnectBackoff := c.ReconnectBackoff
+	if reconnectBackoff == (backoff.Config{}) {
+		reconnectBackoff = backoff.DefaultConfig
+	}
+	dialOpts := []grpc.DialOption{
+		secureOption,
+		grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           reconnectBackoff,
+			MinConnectTimeout: minConnectTimeout,
+		}),
+	}
+	if c.Keepalive != (keepalive.ClientParameters{}) {
+		dialOpts = append(dialOpts, grpc.WithKeepaliveParams(c.Keepalive))
+	}
+	if c.CredentialsRefresher != nil {
+		creds := newRefreshingCredentials(c.Headers, c.CredentialsRefresher)
+		dialOpts = append(dialOpts, grpc.WithChainUnaryInterceptor(creds.intercept))
+	}
+	if c.ProxyURL != "" {
+		dialer, err := proxyDialer(c.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		dialOpts = append(dialOpts, grpc.WithContextDialer(dialer))
+	}
+	dialOpts = append(dialOpts, c.DialOptions...)
+	conn, err := grpc.DialContext(ctx, c.Endpoint, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if c.OnConnectionStateChange != nil {
+		go watchConnectionState(conn, c.OnConnectionStateChange)
+	}
+	return conn, nil
+}
+
+// closeConn closes the channel of an exporter once the exporter has shut
+// down, which also stops watchConnectionState. The OTLP exporters don't
+// close channels they were given, although some versions do, so a channel
+// which is already closing is not an error.
+func closeConn(conn *grpc.ClientConn) error {
+	if err := conn.Close(); err != nil && status.Code(err) != codes.Canceled {
+		return err
+	}
+	return nil
+}
+
+// watchConnectionState calls onChange with every state the channel
+// enters, until the channel is closed.
+func watchConnectionState(conn *grpc.ClientConn, onChange func(connectivity.State)) {
+	state := conn.GetState()
+	for {
+		onChange(state)
+		if state == connectivity.Shutdown {
+			return
+		}
+		conn.WaitForStateChange(context.Background(), state)
+		state = conn.GetState()
+	}
+}
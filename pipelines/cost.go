@@ -0,0 +1,59 @@
+package pipelines
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	metricglobal "go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/metric/unit"
+	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+)
+
+// InstrumentationLibraryKey is the attribute of the span cost metric which
+// identifies the instrumentation library that created the spans.
+const InstrumentationLibraryKey = attribute.Key("otel.library.name")
+
+// costExporter records the estimated encoded size of the spans it exports,
+// by service and instrumentation library, so that the cost of telemetry
+// can be attributed to the code which produces it.
+type costExporter struct {
+	trace.SpanExporter
+	cost metric.Int64Counter
+}
+
+func newCostExporter(next trace.SpanExporter) costExporter {
+	return costExporter{
+		SpanExporter: next,
+		cost: metric.Must(metricglobal.Meter(instrumentationName)).NewInt64Counter(
+			"telemetry.export.span_bytes",
+			metric.WithDescription("Estimated encoded size of exported spans"),
+			metric.WithUnit(unit.Bytes),
+		),
+	}
+}
+
+// costKey groups spans for the cost metric.
+type costKey struct {
+	service string
+	library string
+}
+
+func (e costExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	costs := make(map[costKey]int64)
+	for _, s := range spans {
+		var k costKey
+		if s.Resource() != nil {
+			if v, ok := s.Resource().Set().Value(semconv.ServiceNameKey); ok {
+				k.service = v.AsString()
+			}
+		}
+		k.library = s.InstrumentationLibrary().Name
+		costs[k] += int64(estimateSpanSize(s))
+	}
+	for k, n := range costs {
+		e.cost.Add(ctx, n, semconv.ServiceNameKey.String(k.service), InstrumentationLibraryKey.String(k.library))
+	}
+	return e.SpanExporter.ExportSpans(ctx, spans)
+}
@@ -0,0 +1,93 @@
+package pipelines
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// CredentialsRefresher returns updated export headers, such as a new
+// bearer token, after the ingest endpoint has rejected the current ones.
+// The returned headers are merged over the configured headers.
+type CredentialsRefresher func(context.Context) (map[string]string, error)
+
+// isAuthError reports whether err is an export error caused by rejected
+// credentials.
+func isAuthError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return true
+	}
+	return false
+}
+
+// refreshingCredentials overrides the headers of the calls made by an
+// exporter. When a call fails with an auth error the headers are refreshed
+// and the call is retried once.
+type refreshingCredentials struct {
+	refresh CredentialsRefresher
+
+	mu         sync.RWMutex
+	headers    map[string]string
+	generation int
+}
+
+func newRefreshingCredentials(headers map[string]string, refresh CredentialsRefresher) *refreshingCredentials {
+	return &refreshingCredentials{refresh: refresh, headers: headers}
+}
+
+// outgoing returns ctx with the current headers and their generation.
+func (r *refreshingCredentials) outgoing(ctx context.Context) (context.Context, int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	for k, v := range r.headers {
+		md.Set(k, v)
+	}
+	return metadata.NewOutgoingContext(ctx, md), r.generation
+}
+
+// update refreshes the headers, unless they have already been refreshed
+// since generation was used.
+func (r *refreshingCredentials) update(ctx context.Context, generation int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.generation != generation {
+		return nil
+	}
+	refreshed, err := r.refresh(ctx)
+	if err != nil {
+		return err
+	}
+	headers := make(map[string]string, len(r.headers)+len(refreshed))
+	for k, v := range r.headers {
+		headers[k] = v
+	}
+	for k, v := range refreshed {
+		headers[k] = v
+	}
+	r.headers = headers
+	r.generation++
+	return nil
+}
+
+func (r *refreshingCredentials) intercept(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	callCtx, generation := r.outgoing(ctx)
+	err := invoker(callCtx, method, req, reply, cc, opts...)
+	if !isAuthError(err) {
+		return err
+	}
+	if rerr := r.update(ctx, generation); rerr != nil {
+		otel.Handle(fmt.Errorf("failed to refresh export credentials: %w", rerr))
+		return err
+	}
+	callCtx, _ = r.outgoing(ctx)
+	return invoker(callCtx, method, req, reply, cc, opts...)
+}
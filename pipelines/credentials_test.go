@@ -0,0 +1,44 @@
+package pipelines
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRefreshingCredentials(t *testing.T) {
+	refreshes := 0
+	creds := newRefreshingCredentials(map[string]string{"x-tenant": "acme", "authorization": "Bearer old"}, func(context.Context) (map[string]string, error) {
+		refreshes++
+		return map[string]string{"authorization": "Bearer new"}, nil
+	})
+
+	var seen []string
+	invoker := func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		assert.Equal(t, []string{"acme"}, md.Get("x-tenant"))
+		token := md.Get("authorization")[0]
+		seen = append(seen, token)
+		if token != "Bearer new" {
+			return status.Error(codes.Unauthenticated, "token expired")
+		}
+		return nil
+	}
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", "Bearer old"))
+
+	assert.NoError(t, creds.intercept(ctx, "/export", nil, nil, nil, invoker))
+	assert.NoError(t, creds.intercept(ctx, "/export", nil, nil, nil, invoker))
+	assert.Equal(t, []string{"Bearer old", "Bearer new", "Bearer new"}, seen)
+	assert.Equal(t, 1, refreshes)
+
+	unavailable := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+		return status.Error(codes.Unavailable, "down")
+	}
+	assert.Equal(t, codes.Unavailable, status.Code(creds.intercept(ctx, "/export", nil, nil, nil, unavailable)))
+	assert.Equal(t, 1, refreshes)
+}
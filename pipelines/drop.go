@@ -0,0 +1,72 @@
+package pipelines
+
+import (
+	"context"
+	"path"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// SpanMatcher matches spans by name and attributes. It is converted from
+// launcher.SpanMatcher, which documents the matching rules.
+type SpanMatcher struct {
+	Name       string
+	Attributes map[string]string
+}
+
+// Matches reports whether s matches m.
+func (m SpanMatcher) Matches(s trace.ReadOnlySpan) bool {
+	if m.Name != "" {
+		if ok, _ := path.Match(m.Name, s.Name()); !ok {
+			return false
+		}
+	}
+	if len(m.Attributes) == 0 {
+		return true
+	}
+	matched := 0
+	for _, kv := range s.Attributes() {
+		pattern, found := m.Attributes[string(kv.Key)]
+		if !found {
+			continue
+		}
+		if ok, _ := path.Match(pattern, kv.Value.Emit()); ok {
+			matched++
+		}
+	}
+	return matched == len(m.Attributes)
+}
+
+// dropProcessor discards spans which match any of its matchers, such as
+// health checks, instead of passing them to the next processor.
+type dropProcessor struct {
+	next     trace.SpanProcessor
+	matchers []SpanMatcher
+}
+
+var _ trace.SpanProcessor = &dropProcessor{}
+
+func newDropProcessor(next trace.SpanProcessor, matchers []SpanMatcher) *dropProcessor {
+	return &dropProcessor{next: next, matchers: matchers}
+}
+
+func (p *dropProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p *dropProcessor) OnEnd(s trace.ReadOnlySpan) {
+	for _, m := range p.matchers {
+		if m.Matches(s) {
+			return
+		}
+	}
+	p.next.OnEnd(s)
+}
+
+func (p *dropProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *dropProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
@@ -0,0 +1,80 @@
+package pipelines
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// DynamicResourceAttributes returns resource attributes which may change
+// while the process runs, such as the deployment color. It is called as
+// each span ends, so it should be cheap.
+type DynamicResourceAttributes func() []attribute.KeyValue
+
+// dynamicResourceProcessor exports spans under their resource merged with
+// the current dynamic resource attributes. Merged resources are cached
+// until the dynamic attributes change.
+type dynamicResourceProcessor struct {
+	next  trace.SpanProcessor
+	attrs DynamicResourceAttributes
+
+	mu        sync.Mutex
+	current   attribute.Distinct
+	resources map[*resource.Resource]*resource.Resource
+}
+
+var _ trace.SpanProcessor = &dynamicResourceProcessor{}
+
+func newDynamicResourceProcessor(next trace.SpanProcessor, attrs DynamicResourceAttributes) *dynamicResourceProcessor {
+	return &dynamicResourceProcessor{
+		next:      next,
+		attrs:     attrs,
+		resources: make(map[*resource.Resource]*resource.Resource),
+	}
+}
+
+func (p *dynamicResourceProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p *dynamicResourceProcessor) OnEnd(s trace.ReadOnlySpan) {
+	attrs := p.attrs()
+	if len(attrs) == 0 {
+		p.next.OnEnd(s)
+		return
+	}
+	p.next.OnEnd(spanWithResource{ReadOnlySpan: s, resource: p.resourceFor(s.Resource(), attribute.NewSet(attrs...))})
+}
+
+// resourceFor returns base merged with set. The cache is reset whenever the
+// dynamic attributes change, so it only holds one entry per base resource.
+func (p *dynamicResourceProcessor) resourceFor(base *resource.Resource, set attribute.Set) *resource.Resource {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key := set.Equivalent(); key != p.current {
+		p.current = key
+		p.resources = make(map[*resource.Resource]*resource.Resource)
+	}
+	if r, ok := p.resources[base]; ok {
+		return r
+	}
+	r, err := resource.Merge(base, resource.NewSchemaless(set.ToSlice()...))
+	if err != nil {
+		otel.Handle(err)
+		return base
+	}
+	p.resources[base] = r
+	return r
+}
+
+func (p *dynamicResourceProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *dynamicResourceProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
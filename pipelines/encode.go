@@ -0,0 +1,151 @@
+package pipelines
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// spansToProto encodes spans as OTLP, grouped by resource and
+// instrumentation library. It is the inverse of forwardedSpans.
+func spansToProto(spans []trace.ReadOnlySpan) []*tracepb.ResourceSpans {
+	var (
+		out       []*tracepb.ResourceSpans
+		resources = make(map[attribute.Distinct]*tracepb.ResourceSpans)
+		libraries = make(map[attribute.Distinct]map[instrumentation.Library]*tracepb.InstrumentationLibrarySpans)
+	)
+	for _, s := range spans {
+		res := s.Resource()
+		if res == nil {
+			res = resource.Empty()
+		}
+		key := res.Equivalent()
+		rs, ok := resources[key]
+		if !ok {
+			rs = &tracepb.ResourceSpans{
+				Resource:  &resourcepb.Resource{Attributes: attributesToProto(res.Attributes())},
+				SchemaUrl: res.SchemaURL(),
+			}
+			resources[key] = rs
+			libraries[key] = make(map[instrumentation.Library]*tracepb.InstrumentationLibrarySpans)
+			out = append(out, rs)
+		}
+		lib := s.InstrumentationLibrary()
+		ils, ok := libraries[key][lib]
+		if !ok {
+			ils = &tracepb.InstrumentationLibrarySpans{
+				InstrumentationLibrary: &commonpb.InstrumentationLibrary{Name: lib.Name, Version: lib.Version},
+				SchemaUrl:              lib.SchemaURL,
+			}
+			libraries[key][lib] = ils
+			rs.InstrumentationLibrarySpans = append(rs.InstrumentationLibrarySpans, ils)
+		}
+		ils.Spans = append(ils.Spans, spanToProto(s))
+	}
+	return out
+}
+
+func spanToProto(s trace.ReadOnlySpan) *tracepb.Span {
+	sc := s.SpanContext()
+	traceID, spanID := sc.TraceID(), sc.SpanID()
+	ps := &tracepb.Span{
+		TraceId:                traceID[:],
+		SpanId:                 spanID[:],
+		TraceState:             sc.TraceState().String(),
+		Name:                   s.Name(),
+		Kind:                   tracepb.Span_SpanKind(s.SpanKind()),
+		StartTimeUnixNano:      uint64(s.StartTime().UnixNano()),
+		EndTimeUnixNano:        uint64(s.EndTime().UnixNano()),
+		Attributes:             attributesToProto(s.Attributes()),
+		DroppedAttributesCount: uint32(s.DroppedAttributes()),
+		DroppedEventsCount:     uint32(s.DroppedEvents()),
+		DroppedLinksCount:      uint32(s.DroppedLinks()),
+		Status:                 statusToProto(s.Status()),
+	}
+	if parent := s.Parent(); parent.IsValid() {
+		parentID := parent.SpanID()
+		ps.ParentSpanId = parentID[:]
+	}
+	for _, e := range s.Events() {
+		ps.Events = append(ps.Events, &tracepb.Span_Event{
+			Name:                   e.Name,
+			TimeUnixNano:           uint64(e.Time.UnixNano()),
+			Attributes:             attributesToProto(e.Attributes),
+			DroppedAttributesCount: uint32(e.DroppedAttributeCount),
+		})
+	}
+	for _, l := range s.Links() {
+		traceID, spanID := l.SpanContext.TraceID(), l.SpanContext.SpanID()
+		ps.Links = append(ps.Links, &tracepb.Span_Link{
+			TraceId:                traceID[:],
+			SpanId:                 spanID[:],
+			TraceState:             l.SpanContext.TraceState().String(),
+			Attributes:             attributesToProto(l.Attributes),
+			DroppedAttributesCount: uint32(l.DroppedAttributeCount),
+		})
+	}
+	return ps
+}
+
+func statusToProto(s trace.Status) *tracepb.Status {
+	switch s.Code {
+	case codes.Ok:
+		return &tracepb.Status{Code: tracepb.Status_STATUS_CODE_OK}
+	case codes.Error:
+		return &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR, Message: s.Description}
+	}
+	return &tracepb.Status{Code: tracepb.Status_STATUS_CODE_UNSET}
+}
+
+func attributesToProto(attrs []attribute.KeyValue) []*commonpb.KeyValue {
+	kvs := make([]*commonpb.KeyValue, 0, len(attrs))
+	for _, kv := range attrs {
+		kvs = append(kvs, &commonpb.KeyValue{Key: string(kv.Key), Value: valueToProto(kv.Value)})
+	}
+	return kvs
+}
+
+func valueToProto(v attribute.Value) *commonpb.AnyValue {
+	switch v.Type() {
+	case attribute.BOOL:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v.AsBool()}}
+	case attribute.INT64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: v.AsInt64()}}
+	case attribute.FLOAT64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: v.AsFloat64()}}
+	case attribute.BOOLSLICE:
+		var values []*commonpb.AnyValue
+		for _, b := range v.AsBoolSlice() {
+			values = append(values, valueToProto(attribute.BoolValue(b)))
+		}
+		return arrayValueToProto(values)
+	case attribute.INT64SLICE:
+		var values []*commonpb.AnyValue
+		for _, n := range v.AsInt64Slice() {
+			values = append(values, valueToProto(attribute.Int64Value(n)))
+		}
+		return arrayValueToProto(values)
+	case attribute.FLOAT64SLICE:
+		var values []*commonpb.AnyValue
+		for _, f := range v.AsFloat64Slice() {
+			values = append(values, valueToProto(attribute.Float64Value(f)))
+		}
+		return arrayValueToProto(values)
+	case attribute.STRINGSLICE:
+		var values []*commonpb.AnyValue
+		for _, s := range v.AsStringSlice() {
+			values = append(values, valueToProto(attribute.StringValue(s)))
+		}
+		return arrayValueToProto(values)
+	}
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v.Emit()}}
+}
+
+func arrayValueToProto(values []*commonpb.AnyValue) *commonpb.AnyValue {
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{Values: values}}}
+}
@@ -0,0 +1,22 @@
+package pipelines
+
+// DroppedError is reported through otel.Handle when the pipeline drops
+// telemetry, as opposed to failing to export it, so that error handlers
+// can tell the two apart.
+type DroppedError struct {
+	Err error
+}
+
+func (e DroppedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e DroppedError) Unwrap() error {
+	return e.Err
+}
+
+// Dropped reports that telemetry was dropped. Error handlers which can't
+// import this package match errors with this method.
+func (e DroppedError) Dropped() bool {
+	return true
+}
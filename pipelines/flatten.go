@@ -0,0 +1,91 @@
+package pipelines
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// flatteningProcessor caps the depth of traces by recording spans nested
+// deeper than maxDepth as events on their ancestor at maxDepth, instead of
+// exporting them. The events keep the name, start time, duration,
+// attributes and error status of the spans.
+//
+// Spans nested below an ancestor which has already ended, which is unusual,
+// are exported as normal.
+type flatteningProcessor struct {
+	next     trace.SpanProcessor
+	maxDepth int
+
+	mu    sync.Mutex
+	spans map[oteltrace.SpanID]flattenedSpan
+}
+
+type flattenedSpan struct {
+	depth int
+	// span is kept so that it can be the anchor of its children. The span
+	// in the context passed to OnStart can't be used, as it may be wrapped,
+	// such as by the tracing package's profiler labels.
+	span trace.ReadWriteSpan
+	// anchor is the ancestor at maxDepth, for spans nested below it.
+	anchor trace.ReadWriteSpan
+}
+
+var _ trace.SpanProcessor = &flatteningProcessor{}
+
+func newFlatteningProcessor(next trace.SpanProcessor, maxDepth int) *flatteningProcessor {
+	return &flatteningProcessor{
+		next:     next,
+		maxDepth: maxDepth,
+		spans:    make(map[oteltrace.SpanID]flattenedSpan),
+	}
+}
+
+func (p *flatteningProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+	if !s.SpanContext().IsSampled() {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fs := flattenedSpan{depth: 1, span: s}
+	if ps, ok := p.spans[s.Parent().SpanID()]; ok && s.Parent().IsValid() && !s.Parent().IsRemote() {
+		fs.depth = ps.depth + 1
+		fs.anchor = ps.anchor
+		if ps.depth == p.maxDepth {
+			fs.anchor = ps.span
+		}
+	}
+	p.spans[s.SpanContext().SpanID()] = fs
+}
+
+func (p *flatteningProcessor) OnEnd(s trace.ReadOnlySpan) {
+	p.mu.Lock()
+	fs, ok := p.spans[s.SpanContext().SpanID()]
+	delete(p.spans, s.SpanContext().SpanID())
+	p.mu.Unlock()
+
+	if !ok || fs.depth <= p.maxDepth || fs.anchor == nil || !fs.anchor.EndTime().IsZero() {
+		p.next.OnEnd(s)
+		return
+	}
+	attrs := append(s.Attributes(), attribute.Float64("span.duration_ms", float64(s.EndTime().Sub(s.StartTime()))/float64(time.Millisecond)))
+	if st := s.Status(); st.Code == codes.Error {
+		attrs = append(attrs, attribute.Bool("span.error", true), attribute.String("span.status_message", st.Description))
+	}
+	fs.anchor.AddEvent(s.Name(), oteltrace.WithTimestamp(s.StartTime()), oteltrace.WithAttributes(attrs...))
+}
+
+func (p *flatteningProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *flatteningProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
@@ -0,0 +1,40 @@
+package pipelines
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestFlatteningProcessor(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(newFlatteningProcessor(sr, 2)))
+	tracer := tp.Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "resolve")
+	ctx, level2 := tracer.Start(ctx, "resolve.1")
+	ctx3, level3 := tracer.Start(ctx, "resolve.2", oteltrace.WithAttributes(attribute.String("field", "user")))
+	_, level4 := tracer.Start(ctx3, "resolve.3")
+	level4.End()
+	level3.End()
+	level2.End()
+	root.End()
+
+	ended := sr.Ended()
+	require.Len(t, ended, 2)
+	assert.Equal(t, "resolve.1", ended[0].Name())
+	assert.Equal(t, "resolve", ended[1].Name())
+
+	events := ended[0].Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, "resolve.3", events[0].Name)
+	assert.Equal(t, "resolve.2", events[1].Name)
+	assert.Equal(t, level3.(trace.ReadOnlySpan).StartTime(), events[1].Time)
+	assert.Contains(t, events[1].Attributes, attribute.String("field", "user"))
+}
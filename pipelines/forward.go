@@ -0,0 +1,236 @@
+package pipelines
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// forwardedSpans converts spans encoded as OTLP, such as those produced by
+// an embedded runtime, into spans which can be passed to a span processor.
+// The resource of the spans is merged into base, with the attributes of the
+// forwarded resource taking precedence, so that the spans are attributed
+// to the host process as well as to the runtime which produced them.
+//
+// The spans have already been sampled by the runtime, so they are all
+// marked as sampled.
+func forwardedSpans(base *resource.Resource, rs *tracepb.ResourceSpans) ([]trace.ReadOnlySpan, error) {
+	res, err := resource.Merge(base, resource.NewSchemaless(attributesFromProto(rs.GetResource().GetAttributes())...))
+	if err != nil {
+		return nil, err
+	}
+	var spans []trace.ReadOnlySpan
+	for _, ils := range rs.GetInstrumentationLibrarySpans() {
+		lib := instrumentation.Library{
+			Name:    ils.GetInstrumentationLibrary().GetName(),
+			Version: ils.GetInstrumentationLibrary().GetVersion(),
+		}
+		for _, s := range ils.GetSpans() {
+			span, err := forwardedSpanFromProto(s)
+			if err != nil {
+				return nil, fmt.Errorf("span %q: %w", s.GetName(), err)
+			}
+			span.resource = res
+			span.library = lib
+			spans = append(spans, span)
+		}
+	}
+	return spans, nil
+}
+
+// forwardedSpan is an ended span decoded from OTLP. The SDK only creates
+// ReadOnlySpans for spans it records, so forwardedSpan implements every
+// method itself; the embedded interface, which is always nil, only
+// satisfies the interface's unexported method.
+type forwardedSpan struct {
+	trace.ReadOnlySpan
+	name              string
+	spanContext       oteltrace.SpanContext
+	parent            oteltrace.SpanContext
+	kind              oteltrace.SpanKind
+	startTime         time.Time
+	endTime           time.Time
+	attributes        []attribute.KeyValue
+	links             []trace.Link
+	events            []trace.Event
+	status            trace.Status
+	library           instrumentation.Library
+	resource          *resource.Resource
+	droppedAttributes int
+	droppedLinks      int
+	droppedEvents     int
+}
+
+func (s *forwardedSpan) Name() string                                    { return s.name }
+func (s *forwardedSpan) SpanContext() oteltrace.SpanContext              { return s.spanContext }
+func (s *forwardedSpan) Parent() oteltrace.SpanContext                   { return s.parent }
+func (s *forwardedSpan) SpanKind() oteltrace.SpanKind                    { return s.kind }
+func (s *forwardedSpan) StartTime() time.Time                            { return s.startTime }
+func (s *forwardedSpan) EndTime() time.Time                              { return s.endTime }
+func (s *forwardedSpan) Attributes() []attribute.KeyValue                { return s.attributes }
+func (s *forwardedSpan) Links() []trace.Link                             { return s.links }
+func (s *forwardedSpan) Events() []trace.Event                           { return s.events }
+func (s *forwardedSpan) Status() trace.Status                            { return s.status }
+func (s *forwardedSpan) InstrumentationLibrary() instrumentation.Library { return s.library }
+func (s *forwardedSpan) Resource() *resource.Resource                    { return s.resource }
+func (s *forwardedSpan) DroppedAttributes() int                          { return s.droppedAttributes }
+func (s *forwardedSpan) DroppedLinks() int                               { return s.droppedLinks }
+func (s *forwardedSpan) DroppedEvents() int                              { return s.droppedEvents }
+func (s *forwardedSpan) ChildSpanCount() int                             { return 0 }
+
+func forwardedSpanFromProto(s *tracepb.Span) (*forwardedSpan, error) {
+	sc, err := spanContextFromProto(s.GetTraceId(), s.GetSpanId(), s.GetTraceState())
+	if err != nil {
+		return nil, err
+	}
+	span := &forwardedSpan{
+		name:              s.GetName(),
+		spanContext:       sc.WithTraceFlags(oteltrace.FlagsSampled),
+		kind:              oteltrace.SpanKind(s.GetKind()),
+		startTime:         timeFromProto(s.GetStartTimeUnixNano()),
+		endTime:           timeFromProto(s.GetEndTimeUnixNano()),
+		attributes:        attributesFromProto(s.GetAttributes()),
+		droppedAttributes: int(s.GetDroppedAttributesCount()),
+		droppedEvents:     int(s.GetDroppedEventsCount()),
+		droppedLinks:      int(s.GetDroppedLinksCount()),
+		status:            statusFromProto(s.GetStatus()),
+	}
+	if len(s.GetParentSpanId()) > 0 {
+		parent, err := spanContextFromProto(s.GetTraceId(), s.GetParentSpanId(), "")
+		if err != nil {
+			return nil, fmt.Errorf("parent: %w", err)
+		}
+		span.parent = parent
+	}
+	for _, e := range s.GetEvents() {
+		span.events = append(span.events, trace.Event{
+			Name:                  e.GetName(),
+			Attributes:            attributesFromProto(e.GetAttributes()),
+			DroppedAttributeCount: int(e.GetDroppedAttributesCount()),
+			Time:                  timeFromProto(e.GetTimeUnixNano()),
+		})
+	}
+	for _, l := range s.GetLinks() {
+		lsc, err := spanContextFromProto(l.GetTraceId(), l.GetSpanId(), l.GetTraceState())
+		if err != nil {
+			return nil, fmt.Errorf("link: %w", err)
+		}
+		span.links = append(span.links, trace.Link{
+			SpanContext:           lsc,
+			Attributes:            attributesFromProto(l.GetAttributes()),
+			DroppedAttributeCount: int(l.GetDroppedAttributesCount()),
+		})
+	}
+	return span, nil
+}
+
+func spanContextFromProto(traceID, spanID []byte, traceState string) (oteltrace.SpanContext, error) {
+	var cfg oteltrace.SpanContextConfig
+	if len(traceID) != len(cfg.TraceID) || len(spanID) != len(cfg.SpanID) {
+		return oteltrace.SpanContext{}, fmt.Errorf("invalid trace or span ID")
+	}
+	copy(cfg.TraceID[:], traceID)
+	copy(cfg.SpanID[:], spanID)
+	if traceState != "" {
+		ts, err := oteltrace.ParseTraceState(traceState)
+		if err != nil {
+			return oteltrace.SpanContext{}, err
+		}
+		cfg.TraceState = ts
+	}
+	cfg.Remote = true
+	return oteltrace.NewSpanContext(cfg), nil
+}
+
+func timeFromProto(nanos uint64) time.Time {
+	return time.Unix(0, int64(nanos))
+}
+
+func statusFromProto(s *tracepb.Status) trace.Status {
+	switch s.GetCode() {
+	case tracepb.Status_STATUS_CODE_OK:
+		return trace.Status{Code: codes.Ok}
+	case tracepb.Status_STATUS_CODE_ERROR:
+		return trace.Status{Code: codes.Error, Description: s.GetMessage()}
+	default:
+		return trace.Status{Code: codes.Unset}
+	}
+}
+
+func attributesFromProto(kvs []*commonpb.KeyValue) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(kvs))
+	for _, kv := range kvs {
+		attrs = append(attrs, attribute.KeyValue{
+			Key:   attribute.Key(kv.GetKey()),
+			Value: valueFromProto(kv.GetValue()),
+		})
+	}
+	return attrs
+}
+
+// valueFromProto converts an OTLP attribute value. Arrays whose elements
+// share a type become slices, and values which have no equivalent, such as
+// nested maps, are recorded as strings.
+func valueFromProto(v *commonpb.AnyValue) attribute.Value {
+	switch x := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return attribute.StringValue(x.StringValue)
+	case *commonpb.AnyValue_BoolValue:
+		return attribute.BoolValue(x.BoolValue)
+	case *commonpb.AnyValue_IntValue:
+		return attribute.Int64Value(x.IntValue)
+	case *commonpb.AnyValue_DoubleValue:
+		return attribute.Float64Value(x.DoubleValue)
+	case *commonpb.AnyValue_BytesValue:
+		return attribute.StringValue(base64.StdEncoding.EncodeToString(x.BytesValue))
+	case *commonpb.AnyValue_ArrayValue:
+		return arrayValueFromProto(x.ArrayValue.GetValues())
+	default:
+		return attribute.StringValue(v.String())
+	}
+}
+
+func arrayValueFromProto(values []*commonpb.AnyValue) attribute.Value {
+	var (
+		strings []string
+		bools   []bool
+		ints    []int64
+		floats  []float64
+	)
+	for _, v := range values {
+		switch x := v.GetValue().(type) {
+		case *commonpb.AnyValue_StringValue:
+			strings = append(strings, x.StringValue)
+		case *commonpb.AnyValue_BoolValue:
+			bools = append(bools, x.BoolValue)
+		case *commonpb.AnyValue_IntValue:
+			ints = append(ints, x.IntValue)
+		case *commonpb.AnyValue_DoubleValue:
+			floats = append(floats, x.DoubleValue)
+		}
+	}
+	switch len(values) {
+	case len(strings):
+		return attribute.StringSliceValue(strings)
+	case len(bools):
+		return attribute.BoolSliceValue(bools)
+	case len(ints):
+		return attribute.Int64SliceValue(ints)
+	case len(floats):
+		return attribute.Float64SliceValue(floats)
+	}
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = v.String()
+	}
+	return attribute.StringSliceValue(s)
+}
@@ -0,0 +1,67 @@
+package pipelines
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+func TestForwardedSpans(t *testing.T) {
+	str := func(k, v string) *commonpb.KeyValue {
+		return &commonpb.KeyValue{Key: k, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}}
+	}
+	rs := &tracepb.ResourceSpans{
+		Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{str("service.name", "plugin")}},
+		InstrumentationLibrarySpans: []*tracepb.InstrumentationLibrarySpans{{
+			InstrumentationLibrary: &commonpb.InstrumentationLibrary{Name: "wasm"},
+			Spans: []*tracepb.Span{{
+				TraceId:           []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+				SpanId:            []byte{1, 2, 3, 4, 5, 6, 7, 8},
+				ParentSpanId:      []byte{8, 7, 6, 5, 4, 3, 2, 1},
+				Name:              "evaluate",
+				Kind:              tracepb.Span_SPAN_KIND_INTERNAL,
+				StartTimeUnixNano: 1000,
+				EndTimeUnixNano:   2000,
+				Attributes: []*commonpb.KeyValue{
+					str("policy", "allow"),
+					{Key: "rules", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{Values: []*commonpb.AnyValue{
+						{Value: &commonpb.AnyValue_IntValue{IntValue: 1}},
+						{Value: &commonpb.AnyValue_IntValue{IntValue: 2}},
+					}}}}},
+				},
+				Status: &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR, Message: "denied"},
+			}},
+		}},
+	}
+	base := resource.NewSchemaless(attribute.String("service.name", "api"), attribute.String("host.name", "web-1"))
+
+	spans, err := forwardedSpans(base, rs)
+	require.NoError(t, err)
+	require.Len(t, spans, 1)
+	s := spans[0]
+	assert.Equal(t, "evaluate", s.Name())
+	assert.Equal(t, oteltrace.SpanKindInternal, s.SpanKind())
+	assert.True(t, s.SpanContext().IsSampled())
+	assert.Equal(t, "0807060504030201", s.Parent().SpanID().String())
+	assert.Equal(t, int64(1000), s.StartTime().UnixNano())
+	assert.Equal(t, []attribute.KeyValue{attribute.String("policy", "allow"), attribute.Int64Slice("rules", []int64{1, 2})}, s.Attributes())
+	assert.Equal(t, codes.Error, s.Status().Code)
+	assert.Equal(t, "wasm", s.InstrumentationLibrary().Name)
+
+	v, _ := s.Resource().Set().Value("service.name")
+	assert.Equal(t, "plugin", v.AsString())
+	v, _ = s.Resource().Set().Value("host.name")
+	assert.Equal(t, "web-1", v.AsString())
+
+	rs.InstrumentationLibrarySpans[0].Spans[0].SpanId = []byte{1}
+	_, err = forwardedSpans(base, rs)
+	assert.Error(t, err)
+}
@@ -0,0 +1,154 @@
+package pipelines
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
+)
+
+// SpanStartHook is called with the parent context of every span as it
+// starts, allowing request-scoped values to be copied onto the span.
+type SpanStartHook func(ctx context.Context, span oteltrace.Span)
+
+// spanStartHookProcessor calls the configured hooks when a span starts,
+// before any other processor sees the span.
+type spanStartHookProcessor struct {
+	next  trace.SpanProcessor
+	hooks []SpanStartHook
+}
+
+var _ trace.SpanProcessor = &spanStartHookProcessor{}
+
+func newSpanStartHookProcessor(next trace.SpanProcessor, hooks []SpanStartHook) *spanStartHookProcessor {
+	return &spanStartHookProcessor{next: next, hooks: hooks}
+}
+
+func (p *spanStartHookProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	for _, hook := range p.hooks {
+		hook(parent, s)
+	}
+	p.next.OnStart(parent, s)
+}
+
+func (p *spanStartHookProcessor) OnEnd(s trace.ReadOnlySpan) {
+	p.next.OnEnd(s)
+}
+
+func (p *spanStartHookProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *spanStartHookProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// spanEndHookQueueSize bounds the number of ended spans waiting for the
+// span end hooks.
+const spanEndHookQueueSize = 2048
+
+// SpanEndHook is called with every span after it ends.
+type SpanEndHook func(span trace.ReadOnlySpan)
+
+// spanEndHookProcessor calls the configured hooks with ended spans on a
+// separate goroutine, so that slow hooks never delay the code ending the
+// span or the export of the span. When the hooks fall behind, spans are
+// dropped from the queue rather than blocking. A hook which panics is
+// reported to the OpenTelemetry error handler and does not affect other
+// hooks. Spans which end after Shutdown are not passed to the hooks.
+type spanEndHookProcessor struct {
+	next  trace.SpanProcessor
+	hooks []SpanEndHook
+
+	// queue is never closed, as the tracer provider may still end spans
+	// after the processor has been shut down.
+	queue    chan trace.ReadOnlySpan
+	stopOnce sync.Once
+	stop     chan struct{}
+	stopped  chan struct{}
+}
+
+var _ trace.SpanProcessor = &spanEndHookProcessor{}
+
+func newSpanEndHookProcessor(next trace.SpanProcessor, hooks []SpanEndHook) *spanEndHookProcessor {
+	p := &spanEndHookProcessor{
+		next:    next,
+		hooks:   hooks,
+		queue:   make(chan trace.ReadOnlySpan, spanEndHookQueueSize),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+func (p *spanEndHookProcessor) run() {
+	defer close(p.stopped)
+	for {
+		select {
+		case s := <-p.queue:
+			p.callHooks(s)
+		case <-p.stop:
+			// Call the hooks with the spans which ended before Shutdown.
+			for {
+				select {
+				case s := <-p.queue:
+					p.callHooks(s)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (p *spanEndHookProcessor) callHooks(s trace.ReadOnlySpan) {
+	for _, hook := range p.hooks {
+		p.call(hook, s)
+	}
+}
+
+func (p *spanEndHookProcessor) call(hook SpanEndHook, s trace.ReadOnlySpan) {
+	defer func() {
+		if r := recover(); r != nil {
+			otel.Handle(fmt.Errorf("span end hook panicked: %v", r))
+		}
+	}()
+	hook(s)
+}
+
+func (p *spanEndHookProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p *spanEndHookProcessor) OnEnd(s trace.ReadOnlySpan) {
+	select {
+	case <-p.stop:
+	default:
+		select {
+		case p.queue <- s:
+		default:
+		}
+	}
+	p.next.OnEnd(s)
+}
+
+func (p *spanEndHookProcessor) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+	select {
+	case <-p.stopped:
+	case <-ctx.Done():
+		return multierr.Append(ctx.Err(), p.next.Shutdown(ctx))
+	}
+	return p.next.Shutdown(ctx)
+}
+
+func (p *spanEndHookProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
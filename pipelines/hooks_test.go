@@ -0,0 +1,65 @@
+package pipelines
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSpanEndHookProcessor(t *testing.T) {
+	var names []string
+	sr := tracetest.NewSpanRecorder()
+	p := newSpanEndHookProcessor(sr, []SpanEndHook{
+		func(s sdktrace.ReadOnlySpan) { panic("boom") },
+		func(s sdktrace.ReadOnlySpan) { names = append(names, s.Name()) },
+	})
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(p))
+
+	for _, name := range []string{"a", "b"} {
+		_, span := tp.Tracer("test").Start(context.Background(), name)
+		span.End()
+	}
+	require.NoError(t, tp.Shutdown(context.Background()))
+
+	assert.Equal(t, []string{"a", "b"}, names)
+	assert.Len(t, sr.Ended(), 2)
+}
+
+func TestSpanEndHooksAfterShutdown(t *testing.T) {
+	var mu sync.Mutex
+	var names []string
+	p, err := NewTracePipeline(context.Background(), PipelineConfig{
+		Endpoint:     "127.0.0.1:1",
+		Insecure:     true,
+		Resource:     resource.Empty(),
+		Propagators:  []string{"tracecontext"},
+		BatchTimeout: time.Second,
+		SpanEndHooks: []SpanEndHook{func(s sdktrace.ReadOnlySpan) {
+			mu.Lock()
+			defer mu.Unlock()
+			names = append(names, s.Name())
+		}},
+	})
+	require.NoError(t, err)
+	tracer := otel.Tracer("test")
+	_, span := tracer.Start(context.Background(), "before")
+	span.End()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_ = p.Shutdown(ctx)
+
+	_, span = tracer.Start(context.Background(), "after")
+	assert.NotPanics(t, func() { span.End() })
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"before"}, names)
+}
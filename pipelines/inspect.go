@@ -0,0 +1,123 @@
+package pipelines
+
+import (
+	"context"
+	"sort"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/sdkapi"
+	"go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// ExportSummary describes a batch of telemetry which is about to leave the
+// process. It is converted to launcher.ExportSummary, which documents the
+// fields, before it is passed to the service.
+type ExportSummary struct {
+	Signal                string
+	Destination           string
+	Count                 int
+	AttributeKeys         []string
+	ResourceAttributeKeys []string
+}
+
+// ExportInspector is called with the summary of every batch before it is
+// exported, including batches which are retried.
+type ExportInspector func(ExportSummary)
+
+// keySet collects distinct attribute keys.
+type keySet map[attribute.Key]struct{}
+
+func (k keySet) add(kvs ...attribute.KeyValue) {
+	for _, kv := range kvs {
+		k[kv.Key] = struct{}{}
+	}
+}
+
+func (k keySet) addSet(s *attribute.Set) {
+	for iter := s.Iter(); iter.Next(); {
+		k[iter.Attribute().Key] = struct{}{}
+	}
+}
+
+func (k keySet) sorted() []string {
+	keys := make([]string, 0, len(k))
+	for key := range k {
+		keys = append(keys, string(key))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// inspectingSpanExporter reports a summary of each batch of spans to
+// inspect before exporting it.
+type inspectingSpanExporter struct {
+	trace.SpanExporter
+	destination string
+	inspect     ExportInspector
+}
+
+func (e inspectingSpanExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	attrs, res := keySet{}, keySet{}
+	for _, s := range spans {
+		attrs.add(s.Attributes()...)
+		for _, ev := range s.Events() {
+			attrs.add(ev.Attributes...)
+		}
+		if r := s.Resource(); r != nil {
+			res.addSet(r.Set())
+		}
+	}
+	e.inspect(ExportSummary{
+		Signal:                "traces",
+		Destination:           e.destination,
+		Count:                 len(spans),
+		AttributeKeys:         attrs.sorted(),
+		ResourceAttributeKeys: res.sorted(),
+	})
+	return e.SpanExporter.ExportSpans(ctx, spans)
+}
+
+// inspectingMetricExporter reports a summary of each batch of metric
+// records to inspect before exporting it.
+type inspectingMetricExporter struct {
+	next        metric.Exporter
+	destination string
+	inspect     ExportInspector
+}
+
+var _ metric.Exporter = inspectingMetricExporter{}
+
+func (e inspectingMetricExporter) TemporalityFor(desc *sdkapi.Descriptor, kind aggregation.Kind) aggregation.Temporality {
+	return e.next.TemporalityFor(desc, kind)
+}
+
+func (e inspectingMetricExporter) Export(ctx context.Context, res *resource.Resource, reader metric.InstrumentationLibraryReader) error {
+	var records recordsReader
+	attrs := keySet{}
+	err := reader.ForEach(func(lib instrumentation.Library, r metric.Reader) error {
+		return r.ForEach(e.next, func(rec metric.Record) error {
+			records = append(records, libraryRecord{lib: lib, record: rec})
+			attrs.addSet(rec.Labels())
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	resKeys := keySet{}
+	if res != nil {
+		resKeys.addSet(res.Set())
+	}
+	e.inspect(ExportSummary{
+		Signal:                "metrics",
+		Destination:           e.destination,
+		Count:                 len(records),
+		AttributeKeys:         attrs.sorted(),
+		ResourceAttributeKeys: resKeys.sorted(),
+	})
+	return e.next.Export(ctx, res, records)
+}
@@ -0,0 +1,40 @@
+package pipelines
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestInspectingSpanExporter(t *testing.T) {
+	var summaries []ExportSummary
+	exporter := tracetest.NewInMemoryExporter()
+	tp := trace.NewTracerProvider(
+		trace.WithSyncer(inspectingSpanExporter{
+			SpanExporter: exporter,
+			destination:  "ingest.commonfate.io:443",
+			inspect:      func(s ExportSummary) { summaries = append(summaries, s) },
+		}),
+		trace.WithResource(resource.NewSchemaless(attribute.String("service.name", "api"))),
+	)
+	_, span := tp.Tracer("test").Start(context.Background(), "request", oteltrace.WithAttributes(attribute.String("user.email", "alice@example.com")))
+	span.AddEvent("retry", oteltrace.WithAttributes(attribute.Int("attempt", 2)))
+	span.End()
+
+	require.Len(t, summaries, 1)
+	assert.Equal(t, ExportSummary{
+		Signal:                "traces",
+		Destination:           "ingest.commonfate.io:443",
+		Count:                 1,
+		AttributeKeys:         []string{"attempt", "user.email"},
+		ResourceAttributeKeys: []string{"service.name"},
+	}, summaries[0])
+	assert.Len(t, exporter.GetSpans(), 1)
+}
@@ -0,0 +1,118 @@
+package pipelines
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// valueTruncator shortens string attribute values to at most its length in
+// bytes. The SDK limits the number of attributes, events and links on a
+// span, but not the length of attribute values.
+type valueTruncator int
+
+func (t valueTruncator) truncateString(s string) (string, bool) {
+	if len(s) <= int(t) {
+		return s, false
+	}
+	// Avoid splitting a multi-byte character.
+	n := int(t)
+	for n > 0 && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n], true
+}
+
+func (t valueTruncator) truncateAttributes(attrs []attribute.KeyValue) ([]attribute.KeyValue, bool) {
+	var out []attribute.KeyValue
+	for i, kv := range attrs {
+		var v attribute.Value
+		changed := false
+		switch kv.Value.Type() {
+		case attribute.STRING:
+			var s string
+			s, changed = t.truncateString(kv.Value.AsString())
+			v = attribute.StringValue(s)
+		case attribute.STRINGSLICE:
+			// The slice returned by AsStringSlice is shared with the span.
+			ss := append([]string(nil), kv.Value.AsStringSlice()...)
+			for j := range ss {
+				var c bool
+				ss[j], c = t.truncateString(ss[j])
+				changed = changed || c
+			}
+			v = attribute.StringSliceValue(ss)
+		}
+		if !changed {
+			continue
+		}
+		if out == nil {
+			out = append([]attribute.KeyValue(nil), attrs...)
+		}
+		out[i] = attribute.KeyValue{Key: kv.Key, Value: v}
+	}
+	if out == nil {
+		return attrs, false
+	}
+	return out, true
+}
+
+// truncate returns s, or a copy of it with the long attribute values of the
+// span and its events truncated.
+func (t valueTruncator) truncate(s trace.ReadOnlySpan) trace.ReadOnlySpan {
+	attrs, changed := t.truncateAttributes(s.Attributes())
+	events := s.Events()
+	var copied bool
+	for i, e := range events {
+		eattrs, c := t.truncateAttributes(e.Attributes)
+		if !c {
+			continue
+		}
+		if !copied {
+			events = append([]trace.Event(nil), events...)
+			copied = true
+		}
+		events[i].Attributes = eattrs
+		changed = true
+	}
+	if !changed {
+		return s
+	}
+	return rewrittenSpan{
+		ReadOnlySpan: s,
+		name:         s.Name(),
+		attributes:   attrs,
+		events:       events,
+		status:       s.Status(),
+	}
+}
+
+// truncatingProcessor truncates long string attribute values before spans
+// are passed to the next processor.
+type truncatingProcessor struct {
+	next      trace.SpanProcessor
+	truncator valueTruncator
+}
+
+var _ trace.SpanProcessor = &truncatingProcessor{}
+
+func newTruncatingProcessor(next trace.SpanProcessor, maxLength int) *truncatingProcessor {
+	return &truncatingProcessor{next: next, truncator: valueTruncator(maxLength)}
+}
+
+func (p *truncatingProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p *truncatingProcessor) OnEnd(s trace.ReadOnlySpan) {
+	p.next.OnEnd(p.truncator.truncate(s))
+}
+
+func (p *truncatingProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *truncatingProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
@@ -0,0 +1,37 @@
+package pipelines
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestTruncatingProcessor(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(newTruncatingProcessor(sr, 4)))
+
+	_, span := tp.Tracer("test").Start(context.Background(), "request", oteltrace.WithAttributes(
+		attribute.String("short", "abc"),
+		attribute.String("long", "abcdefgh"),
+		attribute.String("multibyte", "abcé"),
+		attribute.StringSlice("slice", []string{"abcdef", "ab"}),
+	))
+	span.AddEvent("query", oteltrace.WithAttributes(attribute.String("sql", "SELECT 1")))
+	span.End()
+
+	require.Len(t, sr.Ended(), 1)
+	s := sr.Ended()[0]
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("short", "abc"),
+		attribute.String("long", "abcd"),
+		attribute.String("multibyte", "abc"),
+		attribute.StringSlice("slice", []string{"abcd", "ab"}),
+	}, s.Attributes())
+	assert.Equal(t, []attribute.KeyValue{attribute.String("sql", "SELE")}, s.Events()[0].Attributes)
+}
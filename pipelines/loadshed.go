@@ -0,0 +1,73 @@
+package pipelines
+
+import (
+	"context"
+	"path"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/metric/sdkapi"
+	"go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// loadSheddingExporter reduces the metrics exported while the process is
+// overloaded, as reported by a load signal exceeding a threshold. During
+// overload, instruments which are not critical are only exported every
+// downsampleEvery collections, or not at all if it is zero, while critical
+// instruments such as those backing SLOs are always exported.
+//
+// The records of skipped collections are not lost for cumulative
+// instruments, which are exported in full at the next export.
+type loadSheddingExporter struct {
+	next            metric.Exporter
+	signal          func() float64
+	threshold       float64
+	critical        []string
+	downsampleEvery int64
+
+	overloaded int64
+}
+
+var _ metric.Exporter = &loadSheddingExporter{}
+
+func (e *loadSheddingExporter) TemporalityFor(desc *sdkapi.Descriptor, kind aggregation.Kind) aggregation.Temporality {
+	return e.next.TemporalityFor(desc, kind)
+}
+
+func (e *loadSheddingExporter) Export(ctx context.Context, res *resource.Resource, reader metric.InstrumentationLibraryReader) error {
+	if e.signal() <= e.threshold {
+		atomic.StoreInt64(&e.overloaded, 0)
+		return e.next.Export(ctx, res, reader)
+	}
+	// Export everything on the first overloaded collection, and then
+	// once every downsampleEvery collections.
+	n := atomic.AddInt64(&e.overloaded, 1)
+	if e.downsampleEvery > 0 && (n-1)%e.downsampleEvery == 0 {
+		return e.next.Export(ctx, res, reader)
+	}
+
+	var records recordsReader
+	err := reader.ForEach(func(lib instrumentation.Library, r metric.Reader) error {
+		return r.ForEach(e.next, func(rec metric.Record) error {
+			if e.isCritical(rec.Descriptor().Name()) {
+				records = append(records, libraryRecord{lib: lib, record: rec})
+			}
+			return nil
+		})
+	})
+	if err != nil || len(records) == 0 {
+		return err
+	}
+	return e.next.Export(ctx, res, records)
+}
+
+func (e *loadSheddingExporter) isCritical(name string) bool {
+	for _, pattern := range e.critical {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
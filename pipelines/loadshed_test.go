@@ -0,0 +1,53 @@
+package pipelines
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/number"
+	"go.opentelemetry.io/otel/metric/sdkapi"
+	"go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+func TestLoadSheddingExporter(t *testing.T) {
+	var records recordsReader
+	for _, name := range []string{"http.server.duration", "slo.requests", "cache.hits"} {
+		desc := sdkapi.NewDescriptor(name, sdkapi.CounterInstrumentKind, number.Int64Kind, "", "")
+		labels := attribute.NewSet()
+		records = append(records, libraryRecord{
+			lib:    instrumentation.Library{Name: "app"},
+			record: metric.NewRecord(&desc, &labels, nil, time.Time{}, time.Time{}),
+		})
+	}
+
+	load := 0.5
+	next := &rejectingExporter{TemporalitySelector: aggregation.CumulativeTemporalitySelector()}
+	e := &loadSheddingExporter{
+		next:            next,
+		signal:          func() float64 { return load },
+		threshold:       0.8,
+		critical:        []string{"slo.*"},
+		downsampleEvery: 3,
+	}
+	export := func() []string {
+		next.exported = nil
+		require.NoError(t, e.Export(context.Background(), resource.Empty(), records))
+		return next.exported
+	}
+
+	assert.Len(t, export(), 3)
+	load = 0.9
+	assert.Len(t, export(), 3)
+	assert.Equal(t, []string{"app/slo.requests"}, export())
+	assert.Equal(t, []string{"app/slo.requests"}, export())
+	assert.Len(t, export(), 3)
+	load = 0.1
+	assert.Len(t, export(), 3)
+}
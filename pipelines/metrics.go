@@ -3,6 +3,8 @@ package pipelines
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	hostMetrics "go.opentelemetry.io/contrib/instrumentation/host"
@@ -10,15 +12,27 @@ import (
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	metricglobal "go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
 	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
 	processor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
 	selector "go.opentelemetry.io/otel/sdk/metric/selector/simple"
-	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc"
 )
 
-func NewMetricsPipeline(ctx context.Context, c PipelineConfig) (func(context.Context) error, error) {
-	metricExporter, err := newMetricsExporter(ctx, c.Endpoint, c.Insecure, c.Headers)
+// Metric temporalities which may be configured with
+// PipelineConfig.MetricTemporality.
+const (
+	TemporalityCumulative = "cumulative"
+	TemporalityDelta      = "delta"
+)
+
+func NewMetricsPipeline(ctx context.Context, c PipelineConfig) (*Pipeline, error) {
+	temporality, err := temporalitySelector(c.MetricTemporality)
+	if err != nil {
+		return nil, err
+	}
+	metricExporter, err := newReconfigurableMetricExporter(ctx, c, temporality)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create metric exporter: %v", err)
 	}
@@ -34,12 +48,53 @@ func NewMetricsPipeline(ctx context.Context, c PipelineConfig) (func(context.Con
 			return nil, fmt.Errorf("invalid metric reporting period: %v", c.ReportingPeriod)
 		}
 	}
+	views := metricViews(c.MetricViews)
+	var exporter metric.Exporter = metricExporter
+	if len(views) > 0 {
+		exporter = viewExporter{views: views, next: exporter}
+	}
+	if c.ExportInspector != nil {
+		exporter = inspectingMetricExporter{next: exporter, destination: c.Endpoint, inspect: c.ExportInspector}
+	}
+	exporter = bisectingExporter{next: exporter}
+	if c.LoadSignal != nil {
+		exporter = &loadSheddingExporter{
+			next:            exporter,
+			signal:          c.LoadSignal,
+			threshold:       c.LoadThreshold,
+			critical:        c.CriticalInstruments,
+			downsampleEvery: int64(c.LoadSheddingDownsampleEvery),
+		}
+	}
+	counter := &exportCounter{}
+	if c.SelfMetrics {
+		counter.metrics = newSelfMetrics("metrics")
+	}
+	exporter = countingMetricExporter{next: exporter, counter: counter}
+	if c.MetricsPaused != nil {
+		exporter = pausableMetricExporter{next: exporter, paused: c.MetricsPaused}
+	}
+	aggregatorSelector := selector.NewWithInexpensiveDistribution()
+	if len(views) > 0 {
+		aggregatorSelector = viewSelector{views: views, next: aggregatorSelector}
+	}
+	if len(c.HistogramBuckets) > 0 {
+		aggregatorSelector = bucketSelector{buckets: c.HistogramBuckets, next: aggregatorSelector}
+	}
+	var checkpointerFactory metric.CheckpointerFactory = processor.NewFactory(aggregatorSelector, metricExporter)
+	if c.CardinalityLimit > 0 || len(c.InstrumentCardinalityLimits) > 0 {
+		checkpointerFactory = cardinalityCheckpointerFactory{
+			limit:       c.CardinalityLimit,
+			instruments: c.InstrumentCardinalityLimits,
+			next:        checkpointerFactory,
+		}
+	}
+	if len(views) > 0 {
+		checkpointerFactory = viewCheckpointerFactory{views: views, next: checkpointerFactory}
+	}
 	pusher := controller.New(
-		processor.NewFactory(
-			selector.NewWithInexpensiveDistribution(),
-			metricExporter,
-		),
-		controller.WithExporter(metricExporter),
+		checkpointerFactory,
+		controller.WithExporter(exporter),
 		controller.WithResource(c.Resource),
 		controller.WithCollectPeriod(period),
 	)
@@ -52,29 +107,77 @@ func NewMetricsPipeline(ctx context.Context, c PipelineConfig) (func(context.Con
 		return nil, fmt.Errorf("failed to start runtime metrics: %v", err)
 	}
 
-	if err = hostMetrics.Start(hostMetrics.WithMeterProvider(pusher)); err != nil {
-		return nil, fmt.Errorf("failed to start host metrics: %v", err)
+	if c.HostMetrics {
+		if err = hostMetrics.Start(hostMetrics.WithMeterProvider(pusher)); err != nil {
+			return nil, fmt.Errorf("failed to start host metrics: %v", err)
+		}
 	}
 
 	metricglobal.SetMeterProvider(pusher)
-	return func(ctx context.Context) error {
-		_ = pusher.Stop(ctx)
-		return metricExporter.Shutdown(ctx)
+	var mu sync.Mutex
+	return &Pipeline{
+		Shutdown: func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			_ = pusher.Stop(ctx)
+			return metricExporter.Shutdown(ctx)
+		},
+		// The push controller has no flush operation, but stopping it
+		// collects and exports a final time, after which it is restarted.
+		ForceFlush: func(flushCtx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if !pusher.IsRunning() {
+				return nil
+			}
+			if err := pusher.Stop(flushCtx); err != nil {
+				return err
+			}
+			return pusher.Start(ctx)
+		},
+		Stats:  counter.stats,
+		Update: metricExporter.Update,
 	}, nil
 }
 
-func newMetricsExporter(ctx context.Context, endpoint string, insecure bool, headers map[string]string) (*otlpmetric.Exporter, error) {
-	secureOption := otlpmetricgrpc.WithTLSCredentials(credentials.NewClientTLSFromCert(nil, ""))
-	if insecure {
-		secureOption = otlpmetricgrpc.WithInsecure()
+// temporalitySelector returns the selector for the configured temporality.
+// Delta temporality is used for the instruments which record changes, such
+// as counters and histograms, while the up-down counter observers which
+// report totals remain cumulative.
+func temporalitySelector(temporality string) (aggregation.TemporalitySelector, error) {
+	switch strings.ToLower(temporality) {
+	case "", TemporalityCumulative:
+		return aggregation.CumulativeTemporalitySelector(), nil
+	case TemporalityDelta:
+		return aggregation.StatelessTemporalitySelector(), nil
+	default:
+		return nil, fmt.Errorf("invalid metric temporality: %v", temporality)
+	}
+}
+
+// newMetricsExporter returns an OTLP metric exporter and its channel,
+// which the caller closes after shutting down the exporter.
+func newMetricsExporter(ctx context.Context, c PipelineConfig, temporality aggregation.TemporalitySelector) (*otlpmetric.Exporter, *grpc.ClientConn, error) {
+	conn, err := dialExporter(ctx, c)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts := []otlpmetricgrpc.Option{
+		otlpmetricgrpc.WithGRPCConn(conn),
+		otlpmetricgrpc.WithEndpoint(c.Endpoint),
+		otlpmetricgrpc.WithHeaders(c.Headers),
 	}
-	return otlpmetric.New(
+	if c.ExportRetry != nil {
+		opts = append(opts, otlpmetricgrpc.WithRetry(otlpmetricgrpc.RetryConfig(*c.ExportRetry)))
+	}
+	exporter, err := otlpmetric.New(
 		ctx,
-		otlpmetricgrpc.NewClient(
-			secureOption,
-			otlpmetricgrpc.WithEndpoint(endpoint),
-			otlpmetricgrpc.WithHeaders(headers),
-			otlpmetricgrpc.WithCompressor(gzip.Name),
-		),
+		otlpmetricgrpc.NewClient(opts...),
+		otlpmetric.WithMetricAggregationTemporalitySelector(temporality),
 	)
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, err
+	}
+	return exporter, conn, nil
 }
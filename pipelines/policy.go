@@ -0,0 +1,82 @@
+package pipelines
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"google.golang.org/grpc/metadata"
+)
+
+// getPolicyMethod is the ingest service method returning the telemetry
+// policy of the tenant identified by the request headers. The policy is
+// encoded as a google.protobuf.Struct so that fields can be added to it
+// without a change to the client.
+const getPolicyMethod = "/commonfate.ingest.v1.PolicyService/GetPolicy"
+
+// IngestPolicy is the telemetry policy which the ingest service applies to
+// a tenant.
+type IngestPolicy struct {
+	// MaxAttributesPerSpan is the number of attributes recorded on a span,
+	// or zero for the SDK default.
+	MaxAttributesPerSpan int
+	// RequiredHeaders are the headers which exports must include.
+	RequiredHeaders []string
+	// SamplingRatio is the recommended ratio of traces to sample, or zero if
+	// there is no recommendation.
+	SamplingRatio float64
+}
+
+// FetchIngestPolicy fetches the policy for the tenant identified by
+// c.Headers from the ingest service at c.Endpoint.
+func FetchIngestPolicy(ctx context.Context, c PipelineConfig) (*IngestPolicy, error) {
+	c.OnConnectionStateChange = nil
+	conn, err := dialExporter(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var resp structpb.Struct
+	ctx = metadata.NewOutgoingContext(ctx, metadata.New(c.Headers))
+	if err := conn.Invoke(ctx, getPolicyMethod, &empty.Empty{}, &resp); err != nil {
+		return nil, fmt.Errorf("fetching ingest policy: %w", err)
+	}
+	return parseIngestPolicy(&resp)
+}
+
+func parseIngestPolicy(s *structpb.Struct) (*IngestPolicy, error) {
+	var p IngestPolicy
+	for k, v := range s.GetFields() {
+		switch k {
+		case "max_attributes_per_span":
+			n, ok := v.GetKind().(*structpb.Value_NumberValue)
+			if !ok || n.NumberValue < 0 {
+				return nil, fmt.Errorf("invalid ingest policy: %s must be a non-negative number", k)
+			}
+			p.MaxAttributesPerSpan = int(n.NumberValue)
+		case "required_headers":
+			l, ok := v.GetKind().(*structpb.Value_ListValue)
+			if !ok {
+				return nil, fmt.Errorf("invalid ingest policy: %s must be a list", k)
+			}
+			for _, h := range l.ListValue.GetValues() {
+				s, ok := h.GetKind().(*structpb.Value_StringValue)
+				if !ok {
+					return nil, fmt.Errorf("invalid ingest policy: %s must contain strings", k)
+				}
+				p.RequiredHeaders = append(p.RequiredHeaders, s.StringValue)
+			}
+		case "sampling_ratio":
+			n, ok := v.GetKind().(*structpb.Value_NumberValue)
+			if !ok || n.NumberValue < 0 || n.NumberValue > 1 {
+				return nil, fmt.Errorf("invalid ingest policy: %s must be a number between 0 and 1", k)
+			}
+			p.SamplingRatio = n.NumberValue
+		}
+		// Unknown fields are ignored, so that the service can introduce
+		// policies before clients support them.
+	}
+	return &p, nil
+}
@@ -0,0 +1,60 @@
+package pipelines
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestFetchIngestPolicy(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var tenant []string
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+		method, _ := grpc.MethodFromServerStream(stream)
+		assert.Equal(t, getPolicyMethod, method)
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		tenant = md.Get("x-tenant")
+		if err := stream.RecvMsg(&empty.Empty{}); err != nil {
+			return err
+		}
+		return stream.SendMsg(&structpb.Struct{Fields: map[string]*structpb.Value{
+			"max_attributes_per_span": {Kind: &structpb.Value_NumberValue{NumberValue: 64}},
+			"required_headers": {Kind: &structpb.Value_ListValue{ListValue: &structpb.ListValue{Values: []*structpb.Value{
+				{Kind: &structpb.Value_StringValue{StringValue: "x-tenant"}},
+			}}}},
+			"sampling_ratio": {Kind: &structpb.Value_NumberValue{NumberValue: 0.5}},
+			"future_policy":  {Kind: &structpb.Value_BoolValue{BoolValue: true}},
+		}})
+	}))
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	p, err := FetchIngestPolicy(context.Background(), PipelineConfig{
+		Endpoint: lis.Addr().String(),
+		Insecure: true,
+		Headers:  map[string]string{"x-tenant": "acme"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &IngestPolicy{
+		MaxAttributesPerSpan: 64,
+		RequiredHeaders:      []string{"x-tenant"},
+		SamplingRatio:        0.5,
+	}, p)
+	assert.Equal(t, []string{"acme"}, tenant)
+}
+
+func TestParseIngestPolicyInvalid(t *testing.T) {
+	_, err := parseIngestPolicy(&structpb.Struct{Fields: map[string]*structpb.Value{
+		"sampling_ratio": {Kind: &structpb.Value_NumberValue{NumberValue: 2}},
+	}})
+	assert.Error(t, err)
+}
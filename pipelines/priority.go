@@ -0,0 +1,56 @@
+package pipelines
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/multierr"
+)
+
+// priorityProcessor sends spans for which isPriority returns true to high,
+// and all others to low. Each is expected to be a batch span processor with
+// its own queue, so that when low-priority spans are produced faster than
+// they can be exported, the batch processor drops them while the
+// high-priority queue continues to drain.
+type priorityProcessor struct {
+	high, low  trace.SpanProcessor
+	isPriority func(trace.ReadOnlySpan) bool
+}
+
+var _ trace.SpanProcessor = &priorityProcessor{}
+
+func newPriorityProcessor(high, low trace.SpanProcessor, isPriority func(trace.ReadOnlySpan) bool) *priorityProcessor {
+	return &priorityProcessor{high: high, low: low, isPriority: isPriority}
+}
+
+func (p *priorityProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	// The span kind is known at start, but attributes used to prioritise
+	// a span may be set later, so spans are only routed when they end.
+}
+
+func (p *priorityProcessor) OnEnd(s trace.ReadOnlySpan) {
+	if p.isPriority(s) {
+		p.high.OnEnd(s)
+		return
+	}
+	p.low.OnEnd(s)
+}
+
+func (p *priorityProcessor) Shutdown(ctx context.Context) error {
+	return multierr.Append(p.high.Shutdown(ctx), p.low.Shutdown(ctx))
+}
+
+func (p *priorityProcessor) ForceFlush(ctx context.Context) error {
+	return multierr.Append(p.high.ForceFlush(ctx), p.low.ForceFlush(ctx))
+}
+
+// sharedExporter is a span exporter used by several batch span processors,
+// which would each shut it down. The pipeline shuts the underlying exporter
+// down once the processors have been stopped instead.
+type sharedExporter struct {
+	trace.SpanExporter
+}
+
+func (sharedExporter) Shutdown(context.Context) error {
+	return nil
+}
@@ -0,0 +1,68 @@
+package pipelines
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// OTLP export methods, which are called with an empty request to probe
+// the collector.
+const (
+	exportTracesMethod  = "/opentelemetry.proto.collector.trace.v1.TraceService/Export"
+	exportMetricsMethod = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+)
+
+// ProbeTraceEndpoint checks that the collector at c.Endpoint is reachable
+// and accepts the configured headers, by exporting an empty batch of
+// spans. The error describes the likely cause of a failure.
+func ProbeTraceEndpoint(ctx context.Context, c PipelineConfig) error {
+	return probe(ctx, c, exportTracesMethod, &coltracepb.ExportTraceServiceRequest{}, &coltracepb.ExportTraceServiceResponse{})
+}
+
+// ProbeMetricEndpoint is like ProbeTraceEndpoint, but exports an empty
+// batch of metrics.
+func ProbeMetricEndpoint(ctx context.Context, c PipelineConfig) error {
+	return probe(ctx, c, exportMetricsMethod, &colmetricpb.ExportMetricsServiceRequest{}, &colmetricpb.ExportMetricsServiceResponse{})
+}
+
+func probe(ctx context.Context, c PipelineConfig, method string, req, resp interface{}) error {
+	c.OnConnectionStateChange = nil
+	conn, err := dialExporter(ctx, c)
+	if err != nil {
+		return fmt.Errorf("probing %s: %w", c.Endpoint, err)
+	}
+	defer conn.Close()
+
+	ctx = metadata.NewOutgoingContext(ctx, metadata.New(c.Headers))
+	return probeError(c.Endpoint, conn.Invoke(ctx, method, req, resp))
+}
+
+// probeError describes the likely cause of err, returned by a call to the
+// collector at endpoint, and how to resolve it.
+func probeError(endpoint string, err error) error {
+	if err == nil {
+		return nil
+	}
+	s := status.Convert(err)
+	msg := s.Message()
+	switch {
+	case s.Code() == codes.Unauthenticated || s.Code() == codes.PermissionDenied:
+		return fmt.Errorf("%s rejected the credentials: %w. Check the headers configured with OTEL_EXPORTER_OTLP_HEADERS or WithHeaders", endpoint, err)
+	case s.Code() == codes.Unimplemented:
+		return fmt.Errorf("%s does not accept OTLP over gRPC: %w. Check the endpoint", endpoint, err)
+	case strings.Contains(msg, "no such host"):
+		return fmt.Errorf("could not resolve %s: %w. Check the endpoint", endpoint, err)
+	case strings.Contains(msg, "x509") || strings.Contains(msg, "tls:") || strings.Contains(msg, "handshake"):
+		return fmt.Errorf("TLS handshake with %s failed: %w. Check the CA certificate configured with WithCACertFile, and whether the endpoint expects insecure connections", endpoint, err)
+	case s.Code() == codes.Unavailable || s.Code() == codes.DeadlineExceeded:
+		return fmt.Errorf("could not connect to %s: %w. Check the endpoint, and that it is reachable through any proxy or firewall", endpoint, err)
+	}
+	return fmt.Errorf("probing %s: %w", endpoint, err)
+}
@@ -0,0 +1,51 @@
+package pipelines
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type authenticatingTraceService struct {
+	coltracepb.UnimplementedTraceServiceServer
+}
+
+func (authenticatingTraceService) Export(ctx context.Context, _ *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get("x-api-key"); len(v) == 0 || v[0] != "secret" {
+		return nil, status.Error(codes.Unauthenticated, "invalid API key")
+	}
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+func TestProbeTraceEndpoint(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(srv, authenticatingTraceService{})
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c := PipelineConfig{Endpoint: lis.Addr().String(), Insecure: true, Headers: map[string]string{"x-api-key": "secret"}}
+	assert.NoError(t, ProbeTraceEndpoint(ctx, c))
+
+	c.Headers = map[string]string{"x-api-key": "wrong"}
+	err = ProbeTraceEndpoint(ctx, c)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), lis.Addr().String()+" rejected the credentials")
+
+	err = ProbeMetricEndpoint(ctx, c)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not accept OTLP over gRPC")
+}
@@ -0,0 +1,58 @@
+package pipelines
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxyDialer(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+
+	requests := make(chan *http.Request, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		br := bufio.NewReader(conn)
+		req, err := http.ReadRequest(br)
+		if err != nil {
+			return
+		}
+		requests <- req
+		_, _ = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		// Echo the tunnelled data.
+		_, _ = io.Copy(conn, br)
+	}()
+
+	dial, err := proxyDialer("http://user:secret@" + lis.Addr().String())
+	require.NoError(t, err)
+	conn, err := dial(context.Background(), "collector.internal:4317")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	req := <-requests
+	assert.Equal(t, http.MethodConnect, req.Method)
+	assert.Equal(t, "collector.internal:4317", req.Host)
+	assert.Equal(t, "Basic dXNlcjpzZWNyZXQ=", req.Header.Get("Proxy-Authorization"))
+
+	_, err = io.WriteString(conn, "ping")
+	require.NoError(t, err)
+	b := make([]byte, 4)
+	_, err = io.ReadFull(conn, b)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(b))
+
+	_, err = proxyDialer("ftp://proxy.internal")
+	assert.EqualError(t, err, `invalid proxy URL: unsupported scheme "ftp"`)
+}
@@ -0,0 +1,194 @@
+package pipelines
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/metric/sdkapi"
+	"go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/multierr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// ExporterUpdate changes the connection of a running pipeline's exporter.
+type ExporterUpdate struct {
+	// Endpoint replaces the endpoint, unless it is empty.
+	Endpoint string
+	// Headers are merged over the current headers, so that a rotated
+	// token can be updated without repeating the other headers.
+	Headers map[string]string
+}
+
+// exporterConfig is the configuration of an exporter which can be
+// replaced while the pipeline is running. Each exporter has its own gRPC
+// channel, so an update dials a new channel and replaces the exporter
+// only once it has been created; exports in progress complete with the
+// previous exporter, which is then shut down and its channel closed.
+type exporterConfig struct {
+	// mu serializes updates.
+	mu sync.Mutex
+	c  PipelineConfig
+	// retired is set once the current exporter has been replaced, to stop
+	// it reporting the state of its channel.
+	retired *int32
+}
+
+// apply returns the configuration of the exporter which replaces the
+// current one, the configuration with which to dial it, which only reports
+// the state of its channel until it is retired, and its retired flag.
+func (ec *exporterConfig) apply(u ExporterUpdate) (next, dial PipelineConfig, retired *int32) {
+	next = ec.c
+	if u.Endpoint != "" {
+		next.Endpoint = u.Endpoint
+	}
+	if len(u.Headers) > 0 {
+		headers := make(map[string]string, len(next.Headers)+len(u.Headers))
+		for k, v := range next.Headers {
+			headers[k] = v
+		}
+		for k, v := range u.Headers {
+			headers[k] = v
+		}
+		next.Headers = headers
+	}
+	dial, retired = next, new(int32)
+	if onChange := next.OnConnectionStateChange; onChange != nil {
+		dial.OnConnectionStateChange = func(s connectivity.State) {
+			if atomic.LoadInt32(retired) == 0 {
+				onChange(s)
+			}
+		}
+	}
+	return next, dial, retired
+}
+
+// commit records c as the configuration of the current exporter, retiring
+// the previous one.
+func (ec *exporterConfig) commit(c PipelineConfig, retired *int32) {
+	if ec.retired != nil {
+		atomic.StoreInt32(ec.retired, 1)
+	}
+	ec.c, ec.retired = c, retired
+}
+
+// reconfigurableSpanExporter is an OTLP span exporter whose endpoint and
+// headers can be updated.
+type reconfigurableSpanExporter struct {
+	config exporterConfig
+
+	mu       sync.RWMutex
+	exporter *otlptrace.Exporter
+	conn     *grpc.ClientConn
+}
+
+var _ trace.SpanExporter = &reconfigurableSpanExporter{}
+
+func newReconfigurableSpanExporter(ctx context.Context, c PipelineConfig) (*reconfigurableSpanExporter, error) {
+	e := &reconfigurableSpanExporter{config: exporterConfig{c: c}}
+	next, dial, retired := e.config.apply(ExporterUpdate{})
+	exporter, conn, err := newTraceExporter(ctx, dial)
+	if err != nil {
+		return nil, err
+	}
+	e.config.commit(next, retired)
+	e.exporter, e.conn = exporter, conn
+	return e, nil
+}
+
+func (e *reconfigurableSpanExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.exporter.ExportSpans(ctx, spans)
+}
+
+func (e *reconfigurableSpanExporter) Shutdown(ctx context.Context) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	err := e.exporter.Shutdown(ctx)
+	return multierr.Append(err, closeConn(e.conn))
+}
+
+// Update replaces the exporter with one configured by u.
+func (e *reconfigurableSpanExporter) Update(ctx context.Context, u ExporterUpdate) error {
+	e.config.mu.Lock()
+	defer e.config.mu.Unlock()
+	next, dial, retired := e.config.apply(u)
+	exporter, conn, err := newTraceExporter(ctx, dial)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	previous, previousConn := e.exporter, e.conn
+	e.exporter, e.conn = exporter, conn
+	e.config.commit(next, retired)
+	e.mu.Unlock()
+	err = previous.Shutdown(ctx)
+	return multierr.Append(err, closeConn(previousConn))
+}
+
+// reconfigurableMetricExporter is an OTLP metric exporter whose endpoint
+// and headers can be updated.
+type reconfigurableMetricExporter struct {
+	config      exporterConfig
+	temporality aggregation.TemporalitySelector
+
+	mu       sync.RWMutex
+	exporter *otlpmetric.Exporter
+	conn     *grpc.ClientConn
+}
+
+var _ metric.Exporter = &reconfigurableMetricExporter{}
+
+func newReconfigurableMetricExporter(ctx context.Context, c PipelineConfig, temporality aggregation.TemporalitySelector) (*reconfigurableMetricExporter, error) {
+	e := &reconfigurableMetricExporter{config: exporterConfig{c: c}, temporality: temporality}
+	next, dial, retired := e.config.apply(ExporterUpdate{})
+	exporter, conn, err := newMetricsExporter(ctx, dial, temporality)
+	if err != nil {
+		return nil, err
+	}
+	e.config.commit(next, retired)
+	e.exporter, e.conn = exporter, conn
+	return e, nil
+}
+
+func (e *reconfigurableMetricExporter) TemporalityFor(desc *sdkapi.Descriptor, kind aggregation.Kind) aggregation.Temporality {
+	return e.temporality.TemporalityFor(desc, kind)
+}
+
+func (e *reconfigurableMetricExporter) Export(ctx context.Context, res *resource.Resource, reader metric.InstrumentationLibraryReader) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.exporter.Export(ctx, res, reader)
+}
+
+func (e *reconfigurableMetricExporter) Shutdown(ctx context.Context) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	err := e.exporter.Shutdown(ctx)
+	return multierr.Append(err, closeConn(e.conn))
+}
+
+// Update replaces the exporter with one configured by u.
+func (e *reconfigurableMetricExporter) Update(ctx context.Context, u ExporterUpdate) error {
+	e.config.mu.Lock()
+	defer e.config.mu.Unlock()
+	next, dial, retired := e.config.apply(u)
+	exporter, conn, err := newMetricsExporter(ctx, dial, e.temporality)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	previous, previousConn := e.exporter, e.conn
+	e.exporter, e.conn = exporter, conn
+	e.config.commit(next, retired)
+	e.mu.Unlock()
+	err = previous.Shutdown(ctx)
+	return multierr.Append(err, closeConn(previousConn))
+}
@@ -0,0 +1,91 @@
+package pipelines
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+)
+
+func serveTraces(t *testing.T) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(srv, authenticatingTraceService{})
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String()
+}
+
+func TestReconfigurableSpanExporter(t *testing.T) {
+	first, second := serveTraces(t), serveTraces(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	var states []connectivity.State
+	e, err := newReconfigurableSpanExporter(ctx, PipelineConfig{
+		Endpoint: first,
+		Insecure: true,
+		Headers:  map[string]string{"x-api-key": "expired"},
+		OnConnectionStateChange: func(s connectivity.State) {
+			mu.Lock()
+			defer mu.Unlock()
+			states = append(states, s)
+		},
+	})
+	require.NoError(t, err)
+	defer e.Shutdown(context.Background())
+	spans := []trace.ReadOnlySpan{tracetest.SpanStub{Name: "GET /users"}.Snapshot()}
+
+	err = e.ExportSpans(ctx, spans)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	require.NoError(t, e.Update(ctx, ExporterUpdate{Headers: map[string]string{"x-api-key": "secret"}}))
+	assert.NoError(t, e.ExportSpans(ctx, spans))
+
+	previous := e.conn
+	require.NoError(t, e.Update(ctx, ExporterUpdate{Endpoint: second}))
+	assert.NoError(t, e.ExportSpans(ctx, spans))
+	assert.Equal(t, connectivity.Shutdown, previous.GetState())
+	assert.Equal(t, second, e.config.c.Endpoint)
+	assert.Equal(t, map[string]string{"x-api-key": "secret"}, e.config.c.Headers)
+
+	// The channels which were replaced don't report their shutdown.
+	assert.Never(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, s := range states {
+			if s == connectivity.Shutdown {
+				return true
+			}
+		}
+		return false
+	}, 100*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestExporterShutdownClosesConn(t *testing.T) {
+	ctx := context.Background()
+	c := PipelineConfig{Endpoint: serveTraces(t), Insecure: true}
+	se, err := newReconfigurableSpanExporter(ctx, c)
+	require.NoError(t, err)
+	me, err := newReconfigurableMetricExporter(ctx, c, aggregation.CumulativeTemporalitySelector())
+	require.NoError(t, err)
+
+	require.NoError(t, se.Shutdown(ctx))
+	require.NoError(t, me.Shutdown(ctx))
+	assert.Equal(t, connectivity.Shutdown, se.conn.GetState())
+	assert.Equal(t, connectivity.Shutdown, me.conn.GetState())
+}
@@ -0,0 +1,186 @@
+package pipelines
+
+import (
+	"container/list"
+	"context"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// maxBufferedSpans bounds the spans the biased sampling processor holds
+	// while it waits for the local root of their trace to end. When it is
+	// reached the oldest local trace is decided early.
+	maxBufferedSpans = 10000
+
+	// maxDecidedTraces is the number of recent decisions the biased
+	// sampling processor remembers, for spans which end after their local
+	// root.
+	maxDecidedTraces = 4096
+)
+
+// biasedSamplingProcessor makes the sampling decision when a local trace
+// ends rather than when it starts. A local trace is kept, with all of its
+// spans, if any of them ends with an error status or takes longer than the
+// latency threshold; other traces are sampled by trace ID ratio.
+//
+// The ratio decision uses the same trace ID bits as trace.TraceIDRatioBased,
+// so it needs no buffering. Spans of traces which the ratio drops are held
+// until their local root, the span whose parent is remote or absent, ends.
+// Spans which end after their local root follow the decision made for it,
+// unless they are themselves errors or slow.
+type biasedSamplingProcessor struct {
+	next             trace.SpanProcessor
+	upperBound       uint64
+	latencyThreshold time.Duration
+
+	mu       sync.Mutex
+	pending  map[oteltrace.TraceID]*pendingTrace
+	order    *list.List
+	buffered int
+	decided  map[oteltrace.TraceID]bool
+	// recent is a ring of the traces in decided, so that the oldest
+	// decision is forgotten once it is full.
+	recent     [maxDecidedTraces]oteltrace.TraceID
+	nextRecent int
+}
+
+// pendingTrace holds the ended spans of a local trace whose root has not
+// ended.
+type pendingTrace struct {
+	spans []trace.ReadOnlySpan
+	keep  bool
+	elem  *list.Element
+}
+
+var _ trace.SpanProcessor = &biasedSamplingProcessor{}
+
+func newBiasedSamplingProcessor(next trace.SpanProcessor, ratio float64, latencyThreshold time.Duration) *biasedSamplingProcessor {
+	if ratio < 0 {
+		ratio = 0
+	}
+	p := &biasedSamplingProcessor{
+		next:             next,
+		latencyThreshold: latencyThreshold,
+		pending:          make(map[oteltrace.TraceID]*pendingTrace),
+		order:            list.New(),
+		decided:          make(map[oteltrace.TraceID]bool),
+	}
+	if ratio >= 1 {
+		p.upperBound = 1 << 63
+	} else {
+		p.upperBound = uint64(ratio * (1 << 63))
+	}
+	return p
+}
+
+func (p *biasedSamplingProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p *biasedSamplingProcessor) OnEnd(s trace.ReadOnlySpan) {
+	if p.ratioKeeps(s.SpanContext().TraceID()) {
+		p.next.OnEnd(s)
+		return
+	}
+	for _, s := range p.decide(s) {
+		p.next.OnEnd(s)
+	}
+}
+
+// Shutdown forwards the spans of the pending traces which are to be kept,
+// as their local roots will not end.
+func (p *biasedSamplingProcessor) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	var forward []trace.ReadOnlySpan
+	for p.order.Len() > 0 {
+		forward = append(forward, p.evictOldest()...)
+	}
+	p.mu.Unlock()
+
+	for _, s := range forward {
+		p.next.OnEnd(s)
+	}
+	return p.next.Shutdown(ctx)
+}
+
+func (p *biasedSamplingProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// decide buffers a span of a trace which the ratio drops, and returns the
+// spans to forward once the trace is decided.
+func (p *biasedSamplingProcessor) decide(s trace.ReadOnlySpan) []trace.ReadOnlySpan {
+	id := s.SpanContext().TraceID()
+	notable := p.notable(s)
+	root := !s.Parent().IsValid() || s.Parent().IsRemote()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if keep, ok := p.decided[id]; ok {
+		if keep || notable {
+			return []trace.ReadOnlySpan{s}
+		}
+		return nil
+	}
+
+	pt, ok := p.pending[id]
+	if !ok {
+		pt = &pendingTrace{elem: p.order.PushBack(id)}
+		p.pending[id] = pt
+	}
+	pt.spans = append(pt.spans, s)
+	pt.keep = pt.keep || notable
+	p.buffered++
+
+	if root {
+		return p.finish(id, pt)
+	}
+	var forward []trace.ReadOnlySpan
+	for p.buffered > maxBufferedSpans {
+		forward = append(forward, p.evictOldest()...)
+	}
+	return forward
+}
+
+// finish records the decision for a pending trace and returns its spans
+// if it is kept. p.mu must be held.
+func (p *biasedSamplingProcessor) finish(id oteltrace.TraceID, pt *pendingTrace) []trace.ReadOnlySpan {
+	p.order.Remove(pt.elem)
+	delete(p.pending, id)
+	p.buffered -= len(pt.spans)
+
+	delete(p.decided, p.recent[p.nextRecent])
+	p.recent[p.nextRecent] = id
+	p.nextRecent = (p.nextRecent + 1) % maxDecidedTraces
+	p.decided[id] = pt.keep
+
+	if !pt.keep {
+		return nil
+	}
+	return pt.spans
+}
+
+// evictOldest decides the oldest pending trace before its root ends. p.mu
+// must be held.
+func (p *biasedSamplingProcessor) evictOldest() []trace.ReadOnlySpan {
+	id := p.order.Front().Value.(oteltrace.TraceID)
+	return p.finish(id, p.pending[id])
+}
+
+// notable reports whether a span keeps its trace regardless of the ratio.
+func (p *biasedSamplingProcessor) notable(s trace.ReadOnlySpan) bool {
+	if s.Status().Code == codes.Error {
+		return true
+	}
+	return p.latencyThreshold > 0 && s.EndTime().Sub(s.StartTime()) > p.latencyThreshold
+}
+
+func (p *biasedSamplingProcessor) ratioKeeps(traceID oteltrace.TraceID) bool {
+	return binary.BigEndian.Uint64(traceID[0:8])>>1 < p.upperBound
+}
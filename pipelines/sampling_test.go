@@ -0,0 +1,119 @@
+package pipelines
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestBiasedSamplingProcessor(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(newBiasedSamplingProcessor(sr, 0, 50*time.Millisecond)),
+	)
+	tracer := tp.Tracer("test")
+	start := time.Now()
+
+	_, span := tracer.Start(context.Background(), "ok", trace.WithTimestamp(start))
+	span.End(trace.WithTimestamp(start.Add(time.Millisecond)))
+
+	_, span = tracer.Start(context.Background(), "error", trace.WithTimestamp(start))
+	span.SetStatus(codes.Error, "failed")
+	span.End(trace.WithTimestamp(start.Add(time.Millisecond)))
+
+	_, span = tracer.Start(context.Background(), "slow", trace.WithTimestamp(start))
+	span.End(trace.WithTimestamp(start.Add(time.Second)))
+
+	var names []string
+	for _, s := range sr.Ended() {
+		names = append(names, s.Name())
+	}
+	assert.Equal(t, []string{"error", "slow"}, names)
+}
+
+func TestBiasedSamplingProcessorRatio(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(newBiasedSamplingProcessor(sr, 1, 0)),
+	)
+	_, span := tp.Tracer("test").Start(context.Background(), "ok")
+	span.End()
+
+	assert.Len(t, sr.Ended(), 1)
+}
+
+func TestBiasedSamplingProcessorLocalTrace(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(newBiasedSamplingProcessor(sr, 0, 0)),
+	)
+	tracer := tp.Tracer("test")
+
+	// A failed child keeps its parent and siblings.
+	ctx, root := tracer.Start(context.Background(), "request")
+	_, child := tracer.Start(ctx, "query")
+	child.End()
+	_, failed := tracer.Start(ctx, "call")
+	failed.SetStatus(codes.Error, "failed")
+	failed.End()
+	assert.Empty(t, sr.Ended(), "spans are held until the local root ends")
+	root.End()
+	_, late := tracer.Start(ctx, "cleanup")
+	late.End()
+
+	// A healthy trace is dropped as a whole, including late spans.
+	ctx, root = tracer.Start(context.Background(), "healthy")
+	_, child = tracer.Start(ctx, "query")
+	child.End()
+	root.End()
+	_, late = tracer.Start(ctx, "cleanup")
+	late.End()
+
+	var names []string
+	for _, s := range sr.Ended() {
+		names = append(names, s.Name())
+	}
+	assert.Equal(t, []string{"query", "call", "request", "cleanup"}, names)
+}
+
+func TestBiasedSamplingProcessorBounded(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	p := newBiasedSamplingProcessor(sr, 0, 0)
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(p))
+	tracer := tp.Tracer("test")
+
+	// The roots never end, so the oldest traces are decided early.
+	ctx, _ := tracer.Start(context.Background(), "leaked")
+	_, failed := tracer.Start(ctx, "failed")
+	failed.SetStatus(codes.Error, "failed")
+	failed.End()
+	for i := 0; i < maxBufferedSpans; i++ {
+		ctx, _ := tracer.Start(context.Background(), "leaked")
+		_, child := tracer.Start(ctx, "child")
+		child.End()
+	}
+	assert.Equal(t, maxBufferedSpans, p.buffered)
+	assert.Len(t, p.pending, maxBufferedSpans)
+	require.Len(t, sr.Ended(), 1)
+	assert.Equal(t, "failed", sr.Ended()[0].Name())
+}
+
+func TestRecordUnsampled(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(recordUnsampled(sdktrace.NeverSample())),
+		sdktrace.WithSpanProcessor(sr),
+	)
+	_, span := tp.Tracer("test").Start(context.Background(), "unsampled")
+	span.End()
+
+	assert.Len(t, sr.Ended(), 1)
+	assert.False(t, sr.Ended()[0].SpanContext().IsSampled())
+}
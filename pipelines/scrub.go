@@ -0,0 +1,130 @@
+package pipelines
+
+import (
+	"context"
+	"regexp"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// RedactionMarker replaces the text matched by scrubbing patterns.
+const RedactionMarker = "[REDACTED]"
+
+// scrubber replaces the text matching any of its patterns in the names,
+// string attribute values, events and status descriptions of spans.
+type scrubber []*regexp.Regexp
+
+func (sc scrubber) scrubString(s string) (string, bool) {
+	changed := false
+	for _, re := range sc {
+		if re.MatchString(s) {
+			s = re.ReplaceAllLiteralString(s, RedactionMarker)
+			changed = true
+		}
+	}
+	return s, changed
+}
+
+// scrubAttributes returns attrs with their string values scrubbed. The
+// slice is only copied if a value changed.
+func (sc scrubber) scrubAttributes(attrs []attribute.KeyValue) ([]attribute.KeyValue, bool) {
+	var out []attribute.KeyValue
+	for i, kv := range attrs {
+		v, changed := sc.scrubValue(kv.Value)
+		if !changed {
+			continue
+		}
+		if out == nil {
+			out = append([]attribute.KeyValue(nil), attrs...)
+		}
+		out[i] = attribute.KeyValue{Key: kv.Key, Value: v}
+	}
+	if out == nil {
+		return attrs, false
+	}
+	return out, true
+}
+
+func (sc scrubber) scrubValue(v attribute.Value) (attribute.Value, bool) {
+	switch v.Type() {
+	case attribute.STRING:
+		s, changed := sc.scrubString(v.AsString())
+		return attribute.StringValue(s), changed
+	case attribute.STRINGSLICE:
+		// The slice returned by AsStringSlice is shared with the span.
+		ss := append([]string(nil), v.AsStringSlice()...)
+		changed := false
+		for i, s := range ss {
+			var c bool
+			ss[i], c = sc.scrubString(s)
+			changed = changed || c
+		}
+		return attribute.StringSliceValue(ss), changed
+	}
+	return v, false
+}
+
+// scrub returns s, or a copy of it with the matching text replaced.
+func (sc scrubber) scrub(s trace.ReadOnlySpan) trace.ReadOnlySpan {
+	out := rewrittenSpan{ReadOnlySpan: s}
+	var changed, c bool
+	out.name, changed = sc.scrubString(s.Name())
+	out.attributes, c = sc.scrubAttributes(s.Attributes())
+	changed = changed || c
+	out.status = s.Status()
+	out.status.Description, c = sc.scrubString(out.status.Description)
+	changed = changed || c
+
+	events := s.Events()
+	for i, e := range events {
+		name, nameChanged := sc.scrubString(e.Name)
+		attrs, attrsChanged := sc.scrubAttributes(e.Attributes)
+		if !nameChanged && !attrsChanged {
+			continue
+		}
+		if out.events == nil {
+			out.events = append([]trace.Event(nil), events...)
+		}
+		out.events[i].Name = name
+		out.events[i].Attributes = attrs
+		changed = true
+	}
+	if out.events == nil {
+		out.events = events
+	}
+	if !changed {
+		return s
+	}
+	return out
+}
+
+// scrubbingProcessor removes text matching the configured patterns, such as
+// email addresses and access tokens, from spans before they are passed to
+// the next processor.
+type scrubbingProcessor struct {
+	next     trace.SpanProcessor
+	scrubber scrubber
+}
+
+var _ trace.SpanProcessor = &scrubbingProcessor{}
+
+func newScrubbingProcessor(next trace.SpanProcessor, patterns []*regexp.Regexp) *scrubbingProcessor {
+	return &scrubbingProcessor{next: next, scrubber: scrubber(patterns)}
+}
+
+func (p *scrubbingProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p *scrubbingProcessor) OnEnd(s trace.ReadOnlySpan) {
+	p.next.OnEnd(p.scrubber.scrub(s))
+}
+
+func (p *scrubbingProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *scrubbingProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
@@ -0,0 +1,42 @@
+package pipelines
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestScrubbingProcessor(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	email := regexp.MustCompile(`[a-z]+@example\.com`)
+	tp := trace.NewTracerProvider(trace.WithSpanProcessor(newScrubbingProcessor(sr, []*regexp.Regexp{email})))
+
+	_, span := tp.Tracer("test").Start(context.Background(), "invite alice@example.com", oteltrace.WithAttributes(
+		attribute.String("user", "bob@example.com"),
+		attribute.StringSlice("cc", []string{"carol@example.com", "team"}),
+		attribute.Int("count", 2),
+	))
+	span.RecordError(errors.New("no mailbox for dave@example.com"))
+	span.SetStatus(codes.Error, "failed to invite alice@example.com")
+	span.End()
+
+	require.Len(t, sr.Ended(), 1)
+	s := sr.Ended()[0]
+	assert.Equal(t, "invite [REDACTED]", s.Name())
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("user", "[REDACTED]"),
+		attribute.StringSlice("cc", []string{"[REDACTED]", "team"}),
+		attribute.Int("count", 2),
+	}, s.Attributes())
+	assert.Contains(t, s.Events()[0].Attributes, attribute.String("exception.message", "no mailbox for [REDACTED]"))
+	assert.Equal(t, "failed to invite [REDACTED]", s.Status().Description)
+}
@@ -0,0 +1,77 @@
+package pipelines
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	metricglobal "go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/metric/unit"
+)
+
+// selfMetricsMeterName is the meter of the metrics the pipelines record
+// about their own exports, which is kept separate from the meters of the
+// other pipeline metrics so that the self metrics can be selected in views
+// and dashboards.
+const selfMetricsMeterName = instrumentationName + "/self"
+
+// Attributes of the self metrics.
+const (
+	// SignalKey is the signal of the pipeline, "traces" or "metrics".
+	SignalKey = attribute.Key("telemetry.signal")
+	// OutcomeKey is the outcome of an export, "success" or "failure".
+	OutcomeKey = attribute.Key("telemetry.export.outcome")
+)
+
+// selfMetrics records the exports of a pipeline:
+//
+//	telemetry.export.items     spans or metric records exported, by outcome
+//	telemetry.export.failures  exports which failed
+//	telemetry.export.duration  time taken by each export
+//	telemetry.spans.queued     spans queued for export by the batch processor
+//
+// The batch span processor doesn't report the spans it drops when its
+// queue is full, so those are the difference between the spans queued and
+// the spans exported.
+type selfMetrics struct {
+	signal   attribute.KeyValue
+	items    metric.Int64Counter
+	failures metric.Int64Counter
+	duration metric.Float64Histogram
+	queued   metric.Int64Counter
+}
+
+func newSelfMetrics(signal string) *selfMetrics {
+	meter := metric.Must(metricglobal.Meter(selfMetricsMeterName))
+	return &selfMetrics{
+		signal: SignalKey.String(signal),
+		items: meter.NewInt64Counter(
+			"telemetry.export.items",
+			metric.WithDescription("Spans or metric records passed to the exporter"),
+		),
+		failures: meter.NewInt64Counter(
+			"telemetry.export.failures",
+			metric.WithDescription("Exports which failed"),
+		),
+		duration: meter.NewFloat64Histogram(
+			"telemetry.export.duration",
+			metric.WithDescription("Time taken by each export"),
+			metric.WithUnit(unit.Milliseconds),
+		),
+		queued: meter.NewInt64Counter(
+			"telemetry.spans.queued",
+			metric.WithDescription("Spans queued for export by the batch span processor"),
+		),
+	}
+}
+
+func (m *selfMetrics) recordExport(ctx context.Context, n int, elapsed time.Duration, err error) {
+	outcome := OutcomeKey.String("success")
+	if err != nil {
+		outcome = OutcomeKey.String("failure")
+		m.failures.Add(ctx, 1, m.signal)
+	}
+	m.items.Add(ctx, int64(n), m.signal, outcome)
+	m.duration.Record(ctx, float64(elapsed)/float64(time.Millisecond), m.signal, outcome)
+}
@@ -0,0 +1,61 @@
+package pipelines
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	metricglobal "go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// SLABreachedKey is set to true on spans which took longer than the
+// SLA declared for their operation name.
+const SLABreachedKey = attribute.Key("sla.breached")
+
+// slaProcessor compares the duration of ended spans against the expected
+// duration for their name. Breaching spans are annotated before being
+// passed to the next processor and counted in the sla.breaches metric.
+type slaProcessor struct {
+	next     trace.SpanProcessor
+	slas     map[string]time.Duration
+	breaches metric.Int64Counter
+}
+
+var _ trace.SpanProcessor = &slaProcessor{}
+
+func newSLAProcessor(next trace.SpanProcessor, slas map[string]time.Duration) *slaProcessor {
+	meter := metric.Must(metricglobal.Meter(instrumentationName))
+	return &slaProcessor{
+		next: next,
+		slas: slas,
+		breaches: meter.NewInt64Counter(
+			"sla.breaches",
+			metric.WithDescription("Number of spans which exceeded the SLA declared for their operation"),
+		),
+	}
+}
+
+func (p *slaProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p *slaProcessor) OnEnd(s trace.ReadOnlySpan) {
+	sla, ok := p.slas[s.Name()]
+	if !ok || s.EndTime().Sub(s.StartTime()) <= sla {
+		p.next.OnEnd(s)
+		return
+	}
+	operation := attribute.String("operation", s.Name())
+	p.breaches.Add(context.Background(), 1, operation)
+	p.next.OnEnd(withAttributes(s, SLABreachedKey.Bool(true)))
+}
+
+func (p *slaProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *slaProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
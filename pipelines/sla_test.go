@@ -0,0 +1,33 @@
+package pipelines
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSLAProcessor(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(newSLAProcessor(sr, map[string]time.Duration{"db.query": 100 * time.Millisecond})),
+	)
+	tracer := tp.Tracer("test")
+	start := time.Now()
+
+	_, span := tracer.Start(context.Background(), "db.query", trace.WithTimestamp(start))
+	span.End(trace.WithTimestamp(start.Add(10 * time.Millisecond)))
+
+	_, span = tracer.Start(context.Background(), "db.query", trace.WithTimestamp(start))
+	span.End(trace.WithTimestamp(start.Add(time.Second)))
+
+	ended := sr.Ended()
+	require.Len(t, ended, 2)
+	assert.NotContains(t, ended[0].Attributes(), SLABreachedKey.Bool(true))
+	assert.Contains(t, ended[1].Attributes(), SLABreachedKey.Bool(true))
+}
@@ -0,0 +1,54 @@
+package pipelines
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// instrumentationName is used for the meters and tracers created by the
+// pipelines themselves.
+const instrumentationName = "github.com/common-fate/observability/pipelines"
+
+// spanWithAttributes decorates an ended span with additional attributes.
+// Ended spans can no longer be modified, so processors which annotate spans
+// in OnEnd pass this wrapper to the next processor instead.
+type spanWithAttributes struct {
+	trace.ReadOnlySpan
+	extra []attribute.KeyValue
+}
+
+func (s spanWithAttributes) Attributes() []attribute.KeyValue {
+	return append(s.ReadOnlySpan.Attributes(), s.extra...)
+}
+
+// withAttributes returns s decorated with the given attributes.
+func withAttributes(s trace.ReadOnlySpan, attrs ...attribute.KeyValue) trace.ReadOnlySpan {
+	return spanWithAttributes{ReadOnlySpan: s, extra: attrs}
+}
+
+// spanWithResource overrides the resource of an ended span. The OTLP
+// exporter groups spans into ResourceSpans by their resource.
+type spanWithResource struct {
+	trace.ReadOnlySpan
+	resource *resource.Resource
+}
+
+func (s spanWithResource) Resource() *resource.Resource {
+	return s.resource
+}
+
+// rewrittenSpan overrides the fields of an ended span which processors
+// rewrite, such as to remove sensitive text.
+type rewrittenSpan struct {
+	trace.ReadOnlySpan
+	name       string
+	attributes []attribute.KeyValue
+	events     []trace.Event
+	status     trace.Status
+}
+
+func (s rewrittenSpan) Name() string                     { return s.name }
+func (s rewrittenSpan) Attributes() []attribute.KeyValue { return s.attributes }
+func (s rewrittenSpan) Events() []trace.Event            { return s.events }
+func (s rewrittenSpan) Status() trace.Status             { return s.status }
@@ -0,0 +1,88 @@
+package pipelines
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	metricglobal "go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/metric/unit"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// spanMetricsProcessor records request, error and duration (RED) metrics
+// for every ended span, before any sampling decision is applied.
+// Combined with recordUnsampled, this means the metrics describe all
+// traffic rather than only the traces which are exported.
+type spanMetricsProcessor struct {
+	next     trace.SpanProcessor
+	calls    metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+var _ trace.SpanProcessor = &spanMetricsProcessor{}
+
+func newSpanMetricsProcessor(next trace.SpanProcessor) *spanMetricsProcessor {
+	meter := metric.Must(metricglobal.Meter(instrumentationName))
+	return &spanMetricsProcessor{
+		next: next,
+		calls: meter.NewInt64Counter(
+			"span.calls",
+			metric.WithDescription("Number of completed spans, including unsampled spans"),
+		),
+		duration: meter.NewFloat64Histogram(
+			"span.duration",
+			metric.WithDescription("Duration of completed spans, including unsampled spans"),
+			metric.WithUnit(unit.Milliseconds),
+		),
+	}
+}
+
+func (p *spanMetricsProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p *spanMetricsProcessor) OnEnd(s trace.ReadOnlySpan) {
+	attrs := []attribute.KeyValue{
+		attribute.String("span.name", s.Name()),
+		attribute.String("span.kind", s.SpanKind().String()),
+		attribute.String("status.code", s.Status().Code.String()),
+	}
+	ctx := context.Background()
+	p.calls.Add(ctx, 1, attrs...)
+	p.duration.Record(ctx, float64(s.EndTime().Sub(s.StartTime()))/float64(time.Millisecond), attrs...)
+	p.next.OnEnd(s)
+}
+
+func (p *spanMetricsProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *spanMetricsProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// recordUnsampled wraps a sampler so that spans it would drop are still
+// recorded, without being sampled. Recorded spans reach the span
+// processors, allowing spanMetricsProcessor to observe them, but the batch
+// span processor ignores them so they are never exported.
+func recordUnsampled(s trace.Sampler) trace.Sampler {
+	return recordUnsampledSampler{Sampler: s}
+}
+
+type recordUnsampledSampler struct {
+	trace.Sampler
+}
+
+func (s recordUnsampledSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	res := s.Sampler.ShouldSample(p)
+	if res.Decision == trace.Drop {
+		res.Decision = trace.RecordOnly
+	}
+	return res
+}
+
+func (s recordUnsampledSampler) Description() string {
+	return "RecordUnsampled{" + s.Sampler.Description() + "}"
+}
@@ -0,0 +1,105 @@
+package pipelines
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	metricglobal "go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/metric/unit"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// stalenessExporter records the age of the oldest span in each batch when
+// it is exported, which is how long telemetry can take to reach the
+// backend after a span ends.
+type stalenessExporter struct {
+	trace.SpanExporter
+	staleness metric.Float64Histogram
+}
+
+func newStalenessExporter(next trace.SpanExporter) stalenessExporter {
+	return stalenessExporter{
+		SpanExporter: next,
+		staleness: metric.Must(metricglobal.Meter(instrumentationName)).NewFloat64Histogram(
+			"telemetry.export.staleness",
+			metric.WithDescription("Time since the oldest span in each exported batch ended"),
+			metric.WithUnit(unit.Milliseconds),
+		),
+	}
+}
+
+func (e stalenessExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	if len(spans) > 0 {
+		oldest := spans[0].EndTime()
+		for _, s := range spans[1:] {
+			if s.EndTime().Before(oldest) {
+				oldest = s.EndTime()
+			}
+		}
+		e.staleness.Record(ctx, float64(time.Since(oldest))/float64(time.Millisecond))
+	}
+	return e.SpanExporter.ExportSpans(ctx, spans)
+}
+
+// maxStalenessProcessor flushes the next processor, which is expected to
+// be a batch span processor, no later than maxStaleness after a sampled
+// span ends. The batch span processor exports when its batch timeout
+// elapses since the previous export, so a span ending just after an export
+// otherwise waits the whole timeout.
+type maxStalenessProcessor struct {
+	next         trace.SpanProcessor
+	maxStaleness time.Duration
+
+	mu    sync.Mutex
+	timer *time.Timer
+}
+
+var _ trace.SpanProcessor = &maxStalenessProcessor{}
+
+func newMaxStalenessProcessor(next trace.SpanProcessor, maxStaleness time.Duration) *maxStalenessProcessor {
+	return &maxStalenessProcessor{next: next, maxStaleness: maxStaleness}
+}
+
+func (p *maxStalenessProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p *maxStalenessProcessor) OnEnd(s trace.ReadOnlySpan) {
+	p.next.OnEnd(s)
+	if !s.SpanContext().IsSampled() {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.timer == nil {
+		p.timer = time.AfterFunc(p.maxStaleness, p.flush)
+	}
+}
+
+// flush exports the spans queued since the timer was started. Spans which
+// end during the flush start a new timer, which may flush them early.
+func (p *maxStalenessProcessor) flush() {
+	p.mu.Lock()
+	p.timer = nil
+	p.mu.Unlock()
+	if err := p.next.ForceFlush(context.Background()); err != nil {
+		otel.Handle(err)
+	}
+}
+
+func (p *maxStalenessProcessor) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	p.mu.Unlock()
+	return p.next.Shutdown(ctx)
+}
+
+func (p *maxStalenessProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
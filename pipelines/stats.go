@@ -0,0 +1,131 @@
+package pipelines
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/metric/sdkapi"
+	"go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// ExportStats counts the spans or metric records a pipeline has passed to
+// its exporter since it started.
+type ExportStats struct {
+	// Exported is the number which were exported successfully.
+	Exported int64
+	// Failed is the number which failed to export and were dropped.
+	Failed int64
+	// Queued is the number of spans queued for export. It is zero for
+	// metric pipelines.
+	Queued int64
+	// LastError is the error of the most recent failed export, if any.
+	LastError error
+	// LastErrorTime is the time of the most recent failed export.
+	LastErrorTime time.Time
+}
+
+// exportCounter counts the items passed to an exporter, and records them
+// in the self metrics, if enabled.
+type exportCounter struct {
+	exported int64
+	failed   int64
+	queued   int64
+	metrics  *selfMetrics
+
+	mu            sync.Mutex
+	lastErr       error
+	lastErrorTime time.Time
+}
+
+// queue counts a span queued for export.
+func (c *exportCounter) queue() {
+	if c.metrics != nil {
+		c.metrics.queued.Add(context.Background(), 1, c.metrics.signal)
+	}
+	atomic.AddInt64(&c.queued, 1)
+}
+
+func (c *exportCounter) add(ctx context.Context, n int, elapsed time.Duration, err error) {
+	if c.metrics != nil {
+		c.metrics.recordExport(ctx, n, elapsed, err)
+	}
+	if err != nil {
+		atomic.AddInt64(&c.failed, int64(n))
+		c.mu.Lock()
+		c.lastErr, c.lastErrorTime = err, time.Now()
+		c.mu.Unlock()
+		return
+	}
+	atomic.AddInt64(&c.exported, int64(n))
+}
+
+func (c *exportCounter) stats() ExportStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ExportStats{
+		Exported:      atomic.LoadInt64(&c.exported),
+		Failed:        atomic.LoadInt64(&c.failed),
+		Queued:        atomic.LoadInt64(&c.queued),
+		LastError:     c.lastErr,
+		LastErrorTime: c.lastErrorTime,
+	}
+}
+
+// queueCountingProcessor counts the sampled spans passed to the next
+// processor, which queues them for export.
+type queueCountingProcessor struct {
+	trace.SpanProcessor
+	counter *exportCounter
+}
+
+func (p queueCountingProcessor) OnEnd(s trace.ReadOnlySpan) {
+	if s.SpanContext().IsSampled() {
+		p.counter.queue()
+	}
+	p.SpanProcessor.OnEnd(s)
+}
+
+// countingSpanExporter counts the spans exported by the next exporter.
+type countingSpanExporter struct {
+	trace.SpanExporter
+	counter *exportCounter
+}
+
+func (e countingSpanExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	start := time.Now()
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	e.counter.add(ctx, len(spans), time.Since(start), err)
+	return err
+}
+
+// countingMetricExporter counts the records exported by the next exporter.
+type countingMetricExporter struct {
+	next    metric.Exporter
+	counter *exportCounter
+}
+
+var _ metric.Exporter = countingMetricExporter{}
+
+func (e countingMetricExporter) TemporalityFor(desc *sdkapi.Descriptor, kind aggregation.Kind) aggregation.Temporality {
+	return e.next.TemporalityFor(desc, kind)
+}
+
+func (e countingMetricExporter) Export(ctx context.Context, res *resource.Resource, reader metric.InstrumentationLibraryReader) error {
+	n := 0
+	_ = reader.ForEach(func(_ instrumentation.Library, r metric.Reader) error {
+		return r.ForEach(e.next, func(metric.Record) error {
+			n++
+			return nil
+		})
+	})
+	start := time.Now()
+	err := e.next.Export(ctx, res, reader)
+	e.counter.add(ctx, n, time.Since(start), err)
+	return err
+}
@@ -0,0 +1,84 @@
+package pipelines
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// resourcePerAttributeProcessor exports spans under a resource which
+// includes the values of the configured span attributes, such as
+// cf.customer_id. Processes which emit spans on behalf of several tenants
+// use this so that the backend attributes each span to its tenant, as the
+// exporter groups spans into a separate ResourceSpans per resource.
+type resourcePerAttributeProcessor struct {
+	next trace.SpanProcessor
+	keys map[attribute.Key]bool
+
+	mu        sync.Mutex
+	resources map[attribute.Distinct]*resource.Resource
+}
+
+var _ trace.SpanProcessor = &resourcePerAttributeProcessor{}
+
+func newResourcePerAttributeProcessor(next trace.SpanProcessor, keys []string) *resourcePerAttributeProcessor {
+	p := &resourcePerAttributeProcessor{
+		next:      next,
+		keys:      make(map[attribute.Key]bool, len(keys)),
+		resources: make(map[attribute.Distinct]*resource.Resource),
+	}
+	for _, k := range keys {
+		p.keys[attribute.Key(k)] = true
+	}
+	return p
+}
+
+func (p *resourcePerAttributeProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p *resourcePerAttributeProcessor) OnEnd(s trace.ReadOnlySpan) {
+	var attrs []attribute.KeyValue
+	for _, kv := range s.Attributes() {
+		if p.keys[kv.Key] {
+			attrs = append(attrs, kv)
+		}
+	}
+	if len(attrs) == 0 {
+		p.next.OnEnd(s)
+		return
+	}
+	p.next.OnEnd(spanWithResource{ReadOnlySpan: s, resource: p.resourceFor(s.Resource(), attrs)})
+}
+
+// resourceFor returns base merged with attrs, reusing previously merged
+// resources so that spans for the same tenant share a resource.
+func (p *resourcePerAttributeProcessor) resourceFor(base *resource.Resource, attrs []attribute.KeyValue) *resource.Resource {
+	set := attribute.NewSet(attrs...)
+	key := set.Equivalent()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if r, ok := p.resources[key]; ok {
+		return r
+	}
+	r, err := resource.Merge(base, resource.NewSchemaless(set.ToSlice()...))
+	if err != nil {
+		otel.Handle(err)
+		return base
+	}
+	p.resources[key] = r
+	return r
+}
+
+func (p *resourcePerAttributeProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *resourcePerAttributeProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
@@ -0,0 +1,61 @@
+package pipelines
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestResourcePerAttributeProcessor(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", "proxy"))),
+		sdktrace.WithSpanProcessor(newResourcePerAttributeProcessor(sr, []string{"cf.customer_id"})),
+	)
+	tracer := tp.Tracer("test")
+	for _, customer := range []string{"a", "b", "a"} {
+		_, span := tracer.Start(context.Background(), "request", trace.WithAttributes(attribute.String("cf.customer_id", customer)))
+		span.End()
+	}
+	_, span := tracer.Start(context.Background(), "background")
+	span.End()
+
+	ended := sr.Ended()
+	require.Len(t, ended, 4)
+	assert.Contains(t, ended[0].Resource().Attributes(), attribute.String("cf.customer_id", "a"))
+	assert.Contains(t, ended[0].Resource().Attributes(), attribute.String("service.name", "proxy"))
+	assert.Contains(t, ended[1].Resource().Attributes(), attribute.String("cf.customer_id", "b"))
+	assert.Same(t, ended[0].Resource(), ended[2].Resource())
+	assert.Equal(t, 1, ended[3].Resource().Len())
+}
+
+func TestDynamicResourceProcessor(t *testing.T) {
+	color := "blue"
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", "api"))),
+		sdktrace.WithSpanProcessor(newDynamicResourceProcessor(sr, func() []attribute.KeyValue {
+			return []attribute.KeyValue{attribute.String("deployment.color", color)}
+		})),
+	)
+	tracer := tp.Tracer("test")
+	for _, c := range []string{"blue", "blue", "green"} {
+		color = c
+		_, span := tracer.Start(context.Background(), "request")
+		span.End()
+	}
+
+	ended := sr.Ended()
+	require.Len(t, ended, 3)
+	assert.Same(t, ended[0].Resource(), ended[1].Resource())
+	assert.Contains(t, ended[1].Resource().Attributes(), attribute.String("deployment.color", "blue"))
+	assert.Contains(t, ended[2].Resource().Attributes(), attribute.String("deployment.color", "green"))
+	assert.Contains(t, ended[2].Resource().Attributes(), attribute.String("service.name", "api"))
+}
@@ -0,0 +1,45 @@
+package pipelines
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// transportCredentials returns the dial option which secures the exporter
+// channel. TLS is used unless c.Insecure is set, configured by c.TLSConfig
+// if set, trusting the CAs in c.CACertificateFile if set, and presenting
+// the client certificate in c.ClientCertificateFile and c.ClientKeyFile
+// for mutual TLS.
+func transportCredentials(c PipelineConfig) (grpc.DialOption, error) {
+	if c.Insecure {
+		return grpc.WithInsecure(), nil
+	}
+	cfg := &tls.Config{}
+	if c.TLSConfig != nil {
+		cfg = c.TLSConfig.Clone()
+	}
+	if c.CACertificateFile != "" {
+		pem, err := ioutil.ReadFile(c.CACertificateFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("loading CA certificate: no certificates found in %s", c.CACertificateFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if c.ClientCertificateFile != "" || c.ClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.ClientCertificateFile, c.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		cfg.Certificates = append(cfg.Certificates, cert)
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(cfg)), nil
+}
@@ -4,29 +4,118 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/common-fate/observability/propagators"
+	"go.opentelemetry.io/contrib/propagators/aws/xray"
 	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/jaeger"
 	"go.opentelemetry.io/contrib/propagators/ot"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/trace"
-	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/encoding/gzip"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
 )
 
-func NewTracePipeline(ctx context.Context, c PipelineConfig) (func(context.Context) error, error) {
-	spanExporter, err := newTraceExporter(ctx, c.Endpoint, c.Insecure, c.Headers)
+func NewTracePipeline(ctx context.Context, c PipelineConfig) (*Pipeline, error) {
+	spanExporter, err := newReconfigurableSpanExporter(ctx, c)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create span exporter: %v", err)
 	}
 
-	bsp := trace.NewBatchSpanProcessor(spanExporter, trace.WithBatchTimeout(c.BatchTimeout))
-	tp := trace.NewTracerProvider(
-		trace.WithSampler(trace.AlwaysSample()),
-		trace.WithSpanProcessor(bsp),
+	var exporter trace.SpanExporter = newStalenessExporter(spanExporter)
+	// Batches are buffered to disk after the staleness exporter has seen
+	// them fail.
+	if c.DiskBufferDir != "" {
+		if exporter, err = newWALExporter(exporter, c.DiskBufferDir, c.DiskBufferMaxBytes); err != nil {
+			return nil, err
+		}
+	}
+	if c.SpanCostMetrics {
+		exporter = newCostExporter(exporter)
+	}
+	if c.ExportInspector != nil {
+		exporter = inspectingSpanExporter{SpanExporter: exporter, destination: c.Endpoint, inspect: c.ExportInspector}
+	}
+	counter := &exportCounter{}
+	if c.SelfMetrics {
+		counter.metrics = newSelfMetrics("traces")
+	}
+	exporter = countingSpanExporter{SpanExporter: exporter, counter: counter}
+	newBatchProcessor := func(e trace.SpanExporter) trace.SpanProcessor {
+		opts := append([]trace.BatchSpanProcessorOption{trace.WithBatchTimeout(c.BatchTimeout)}, c.BatchSpanProcessorOptions...)
+		var bsp trace.SpanProcessor = queueCountingProcessor{
+			SpanProcessor: trace.NewBatchSpanProcessor(e, opts...),
+			counter:       counter,
+		}
+		if c.MaxExportBatchBytes > 0 {
+			bsp = newBatchSizeProcessor(bsp, c.MaxExportBatchBytes)
+		}
+		if c.MaxExportStaleness > 0 {
+			bsp = newMaxStalenessProcessor(bsp, c.MaxExportStaleness)
+		}
+		return bsp
+	}
+	var sp trace.SpanProcessor
+	switch {
+	case c.SyncExport:
+		sp = queueCountingProcessor{SpanProcessor: trace.NewSimpleSpanProcessor(exporter), counter: counter}
+	case c.PrioritySpan != nil:
+		shared := sharedExporter{exporter}
+		sp = newPriorityProcessor(newBatchProcessor(shared), newBatchProcessor(shared), c.PrioritySpan)
+	default:
+		sp = newBatchProcessor(exporter)
+	}
+	// Dynamic attributes are merged after the per-tenant resources are
+	// resolved, as those are cached on the assumption that the base
+	// resource never changes.
+	if c.DynamicResourceAttributes != nil {
+		sp = newDynamicResourceProcessor(sp, c.DynamicResourceAttributes)
+	}
+	if len(c.ResourceFromSpanAttributes) > 0 {
+		sp = newResourcePerAttributeProcessor(sp, c.ResourceFromSpanAttributes)
+	}
+	// Forwarded spans have already been sampled, so they skip the sampling
+	// and per-span analysis below, but are filtered like local spans.
+	forwardTo := withSpanFilters(sp, c)
+	if c.BiasedSampling {
+		sp = newBiasedSamplingProcessor(sp, c.BiasedSamplingRatio, c.BiasedSamplingLatencyThreshold)
+	}
+	// SLA checks run before sampling so that breaches are counted for
+	// every span, not just the exported ones.
+	if len(c.OperationSLAs) > 0 {
+		sp = newSLAProcessor(sp, c.OperationSLAs)
+	}
+	sampler := trace.AlwaysSample()
+	if c.Sampler != nil {
+		sampler = c.Sampler
+	}
+	if c.SpanMetrics {
+		sampler = recordUnsampled(sampler)
+		sp = newSpanMetricsProcessor(sp)
+	}
+	if len(c.SpanEndHooks) > 0 {
+		sp = newSpanEndHookProcessor(sp, c.SpanEndHooks)
+	}
+	// Spans are filtered before they are passed to the span end hooks
+	// or recorded in span metrics.
+	sp = withSpanFilters(sp, c)
+	if c.MaxSpanDepth > 0 {
+		sp = newFlatteningProcessor(sp, c.MaxSpanDepth)
+	}
+	if len(c.SpanStartHooks) > 0 {
+		sp = newSpanStartHookProcessor(sp, c.SpanStartHooks)
+	}
+	tpOpts := []trace.TracerProviderOption{
+		trace.WithSampler(sampler),
+		trace.WithSpanProcessor(sp),
 		trace.WithResource(c.Resource),
-	)
+	}
+	if c.SpanLimits != (trace.SpanLimits{}) {
+		tpOpts = append(tpOpts, trace.WithSpanLimits(c.SpanLimits))
+	}
+	tp := trace.NewTracerProvider(tpOpts...)
 
 	if err = configurePropagators(c); err != nil {
 		return nil, err
@@ -34,35 +123,80 @@ func NewTracePipeline(ctx context.Context, c PipelineConfig) (func(context.Conte
 
 	otel.SetTracerProvider(tp)
 
-	return func(ctx context.Context) error {
-		_ = bsp.Shutdown(ctx)
-		return spanExporter.Shutdown(ctx)
+	return &Pipeline{
+		Shutdown: func(ctx context.Context) error {
+			_ = sp.Shutdown(ctx)
+			return spanExporter.Shutdown(ctx)
+		},
+		ForceFlush: tp.ForceFlush,
+		Stats:      counter.stats,
+		Update:     spanExporter.Update,
+		ForwardTraces: func(ctx context.Context, rs *tracepb.ResourceSpans) error {
+			spans, err := forwardedSpans(c.Resource, rs)
+			if err != nil {
+				return err
+			}
+			for _, s := range spans {
+				forwardTo.OnEnd(s)
+			}
+			return nil
+		},
 	}, nil
 }
 
-func newTraceExporter(ctx context.Context, endpoint string, insecure bool, headers map[string]string) (*otlptrace.Exporter, error) {
-	secureOption := otlptracegrpc.WithTLSCredentials(credentials.NewClientTLSFromCert(nil, ""))
-	if insecure {
-		secureOption = otlptracegrpc.WithInsecure()
-	}
-	return otlptrace.New(
-		ctx,
-		otlptracegrpc.NewClient(
-			secureOption,
-			otlptracegrpc.WithEndpoint(endpoint),
-			otlptracegrpc.WithHeaders(headers),
-			otlptracegrpc.WithCompressor(gzip.Name),
-		),
-	)
+// withSpanFilters wraps next in the processors which drop spans and
+// truncate, scrub and remove attributes, which apply to both local and
+// forwarded spans.
+func withSpanFilters(next trace.SpanProcessor, c PipelineConfig) trace.SpanProcessor {
+	if c.AttributeValueLengthLimit > 0 {
+		next = newTruncatingProcessor(next, c.AttributeValueLengthLimit)
+	}
+	if len(c.ScrubPatterns) > 0 {
+		next = newScrubbingProcessor(next, c.ScrubPatterns)
+	}
+	if c.AttributeFilter != nil {
+		next = newAttributeFilterProcessor(next, c.AttributeFilter)
+	}
+	if len(c.DroppedSpans) > 0 {
+		next = newDropProcessor(next, c.DroppedSpans)
+	}
+	return next
+}
+
+// newTraceExporter returns an OTLP span exporter and its channel, which
+// the caller closes after shutting down the exporter.
+func newTraceExporter(ctx context.Context, c PipelineConfig) (*otlptrace.Exporter, *grpc.ClientConn, error) {
+	conn, err := dialExporter(ctx, c)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts := []otlptracegrpc.Option{
+		otlptracegrpc.WithGRPCConn(conn),
+		otlptracegrpc.WithEndpoint(c.Endpoint),
+		otlptracegrpc.WithHeaders(c.Headers),
+	}
+	if c.ExportRetry != nil {
+		opts = append(opts, otlptracegrpc.WithRetry(otlptracegrpc.RetryConfig(*c.ExportRetry)))
+	}
+	exporter, err := otlptrace.New(ctx, otlptracegrpc.NewClient(opts...))
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, err
+	}
+	return exporter, conn, nil
 }
 
 // configurePropagators configures B3 propagation by default
 func configurePropagators(c PipelineConfig) error {
 	propagatorsMap := map[string]propagation.TextMapPropagator{
 		"b3":           b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)),
+		"b3single":     b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)),
 		"baggage":      propagation.Baggage{},
 		"tracecontext": propagation.TraceContext{},
 		"ottrace":      ot.OT{},
+		"xray":         xray.Propagator{},
+		"cfbinary":     propagators.CFBinary{},
+		"jaeger":       jaeger.Jaeger{},
 	}
 	var props []propagation.TextMapPropagator
 	for _, key := range c.Propagators {
@@ -71,8 +205,9 @@ func configurePropagators(c PipelineConfig) error {
 			props = append(props, prop)
 		}
 	}
+	props = append(props, c.TextMapPropagators...)
 	if len(props) == 0 {
-		return fmt.Errorf("invalid configuration: unsupported propagators. Supported options: b3,baggage,tracecontext,ottrace")
+		return fmt.Errorf("invalid configuration: unsupported propagators. Supported options: b3,b3single,baggage,tracecontext,ottrace,xray,cfbinary,jaeger")
 	}
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
 		props...,
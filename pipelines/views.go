@@ -0,0 +1,168 @@
+package pipelines
+
+import (
+	"context"
+	"path"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/sdkapi"
+	"go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/histogram"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/lastvalue"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/sum"
+	"go.opentelemetry.io/otel/sdk/metric/processor/reducer"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// Aggregations which a MetricView can select.
+const (
+	AggregationDefault   = ""
+	AggregationSum       = "sum"
+	AggregationLastValue = "last_value"
+	AggregationHistogram = "histogram"
+	AggregationDrop      = "drop"
+)
+
+// MetricView changes how matching instruments are exported. It is
+// converted from launcher.MetricView, which documents the fields. The
+// metrics SDK does not support views, so they are applied by the pipeline.
+type MetricView struct {
+	Instrument       string
+	Name             string
+	AttributeKeys    []string
+	Aggregation      string
+	HistogramBuckets []float64
+}
+
+// metricViews applies the first matching view to each instrument.
+type metricViews []MetricView
+
+func (v metricViews) viewFor(desc *sdkapi.Descriptor) (MetricView, bool) {
+	for _, view := range v {
+		if ok, _ := path.Match(view.Instrument, desc.Name()); ok {
+			return view, true
+		}
+	}
+	return MetricView{}, false
+}
+
+// viewSelector selects the aggregation of the instruments with a view,
+// and uses next for all others.
+type viewSelector struct {
+	views metricViews
+	next  metric.AggregatorSelector
+}
+
+var _ metric.AggregatorSelector = viewSelector{}
+
+func (s viewSelector) AggregatorFor(desc *sdkapi.Descriptor, aggPtrs ...*metric.Aggregator) {
+	view, ok := s.views.viewFor(desc)
+	if !ok {
+		s.next.AggregatorFor(desc, aggPtrs...)
+		return
+	}
+	switch view.Aggregation {
+	case AggregationDrop:
+		// Leaving the aggregators unset disables the instrument.
+	case AggregationSum:
+		aggs := sum.New(len(aggPtrs))
+		for i := range aggPtrs {
+			*aggPtrs[i] = &aggs[i]
+		}
+	case AggregationLastValue:
+		aggs := lastvalue.New(len(aggPtrs))
+		for i := range aggPtrs {
+			*aggPtrs[i] = &aggs[i]
+		}
+	case AggregationHistogram:
+		var opts []histogram.Option
+		if len(view.HistogramBuckets) > 0 {
+			opts = append(opts, histogram.WithExplicitBoundaries(view.HistogramBuckets))
+		}
+		aggs := histogram.New(len(aggPtrs), desc, opts...)
+		for i := range aggPtrs {
+			*aggPtrs[i] = &aggs[i]
+		}
+	default:
+		s.next.AggregatorFor(desc, aggPtrs...)
+	}
+}
+
+// bucketSelector aggregates the instruments with configured bucket
+// boundaries as histograms, and uses next for all others.
+type bucketSelector struct {
+	buckets map[string][]float64
+	next    metric.AggregatorSelector
+}
+
+var _ metric.AggregatorSelector = bucketSelector{}
+
+func (s bucketSelector) AggregatorFor(desc *sdkapi.Descriptor, aggPtrs ...*metric.Aggregator) {
+	buckets, ok := s.buckets[desc.Name()]
+	if !ok {
+		s.next.AggregatorFor(desc, aggPtrs...)
+		return
+	}
+	aggs := histogram.New(len(aggPtrs), desc, histogram.WithExplicitBoundaries(buckets))
+	for i := range aggPtrs {
+		*aggPtrs[i] = &aggs[i]
+	}
+}
+
+// LabelFilterFor implements reducer.LabelFilterSelector, removing the
+// attributes which a view does not keep.
+func (v metricViews) LabelFilterFor(desc *sdkapi.Descriptor) attribute.Filter {
+	view, ok := v.viewFor(desc)
+	if !ok || view.AttributeKeys == nil {
+		return func(attribute.KeyValue) bool { return true }
+	}
+	keep := make(map[attribute.Key]bool, len(view.AttributeKeys))
+	for _, k := range view.AttributeKeys {
+		keep[attribute.Key(k)] = true
+	}
+	return func(kv attribute.KeyValue) bool { return keep[kv.Key] }
+}
+
+// viewCheckpointerFactory removes the attributes which the views do not
+// keep before measurements are aggregated for export.
+type viewCheckpointerFactory struct {
+	views metricViews
+	next  metric.CheckpointerFactory
+}
+
+func (f viewCheckpointerFactory) NewCheckpointer() metric.Checkpointer {
+	return reducer.New(f.views, f.next.NewCheckpointer())
+}
+
+// viewExporter renames the instruments with a view which sets a name.
+type viewExporter struct {
+	views metricViews
+	next  metric.Exporter
+}
+
+var _ metric.Exporter = viewExporter{}
+
+func (e viewExporter) TemporalityFor(desc *sdkapi.Descriptor, kind aggregation.Kind) aggregation.Temporality {
+	return e.next.TemporalityFor(desc, kind)
+}
+
+func (e viewExporter) Export(ctx context.Context, res *resource.Resource, reader metric.InstrumentationLibraryReader) error {
+	var records recordsReader
+	err := reader.ForEach(func(lib instrumentation.Library, r metric.Reader) error {
+		return r.ForEach(e.next, func(rec metric.Record) error {
+			if view, ok := e.views.viewFor(rec.Descriptor()); ok && view.Name != "" {
+				d := rec.Descriptor()
+				renamed := sdkapi.NewDescriptor(view.Name, d.InstrumentKind(), d.NumberKind(), d.Description(), d.Unit())
+				rec = metric.NewRecord(&renamed, rec.Labels(), rec.Aggregation(), rec.StartTime(), rec.EndTime())
+			}
+			records = append(records, libraryRecord{lib: lib, record: rec})
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return e.next.Export(ctx, res, records)
+}
@@ -0,0 +1,99 @@
+package pipelines
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+	processor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
+	"go.opentelemetry.io/otel/sdk/metric/processor/processortest"
+	selector "go.opentelemetry.io/otel/sdk/metric/selector/simple"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+func TestMetricViews(t *testing.T) {
+	ctx := context.Background()
+	views := metricViews{
+		{Instrument: "http.*", Name: "requests.sum", AttributeKeys: []string{"route"}},
+		{Instrument: "debug.*", Aggregation: AggregationDrop},
+	}
+	exporter := processortest.New(aggregation.CumulativeTemporalitySelector(), attribute.DefaultEncoder())
+	cont := controller.New(
+		viewCheckpointerFactory{
+			views: views,
+			next:  processor.NewFactory(viewSelector{views: views, next: selector.NewWithInexpensiveDistribution()}, exporter),
+		},
+		controller.WithExporter(viewExporter{views: views, next: exporter}),
+		controller.WithResource(resource.Empty()),
+	)
+	require.NoError(t, cont.Start(ctx))
+
+	meter := otelmetric.Must(cont.Meter("test"))
+	requests := meter.NewInt64Counter("http.requests")
+	requests.Add(ctx, 1, attribute.String("route", "/users"), attribute.String("user", "alice"))
+	requests.Add(ctx, 2, attribute.String("route", "/users"), attribute.String("user", "bob"))
+	meter.NewInt64Counter("debug.allocations").Add(ctx, 5)
+
+	require.NoError(t, cont.Stop(ctx))
+	assert.Equal(t, map[string]float64{"requests.sum/route=/users/": 3}, exporter.Values())
+}
+
+// histogramExporter records the bucket counts of exported histograms.
+type histogramExporter struct {
+	aggregation.TemporalitySelector
+	buckets map[string]aggregation.Buckets
+}
+
+func (e *histogramExporter) Export(_ context.Context, _ *resource.Resource, reader metric.InstrumentationLibraryReader) error {
+	return reader.ForEach(func(_ instrumentation.Library, r metric.Reader) error {
+		return r.ForEach(e, func(rec metric.Record) error {
+			h, ok := rec.Aggregation().(aggregation.Histogram)
+			if !ok {
+				return nil
+			}
+			buckets, err := h.Histogram()
+			if err != nil {
+				return err
+			}
+			e.buckets[rec.Descriptor().Name()] = buckets
+			return nil
+		})
+	})
+}
+
+func TestBucketSelector(t *testing.T) {
+	ctx := context.Background()
+	exporter := &histogramExporter{
+		TemporalitySelector: aggregation.CumulativeTemporalitySelector(),
+		buckets:             make(map[string]aggregation.Buckets),
+	}
+	sel := bucketSelector{
+		buckets: map[string][]float64{"http.server.duration": {10, 100}},
+		next:    selector.NewWithInexpensiveDistribution(),
+	}
+	cont := controller.New(
+		processor.NewFactory(sel, exporter),
+		controller.WithExporter(exporter),
+		controller.WithResource(resource.Empty()),
+	)
+	require.NoError(t, cont.Start(ctx))
+
+	meter := otelmetric.Must(cont.Meter("test"))
+	latency := meter.NewFloat64Histogram("http.server.duration")
+	for _, v := range []float64{5, 50, 500, 60} {
+		latency.Record(ctx, v)
+	}
+	meter.NewFloat64Histogram("db.duration").Record(ctx, 5)
+	require.NoError(t, cont.Stop(ctx))
+
+	require.Len(t, exporter.buckets, 1)
+	assert.Equal(t, []float64{10, 100}, exporter.buckets["http.server.duration"].Boundaries)
+	assert.Equal(t, []uint64{1, 2, 1}, exporter.buckets["http.server.duration"].Counts)
+}
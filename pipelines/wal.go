@@ -0,0 +1,229 @@
+package pipelines
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"go.uber.org/multierr"
+)
+
+// DefaultDiskBufferMaxBytes is the size of the disk buffer when none is
+// configured.
+const DefaultDiskBufferMaxBytes = 64 << 20
+
+const (
+	walSuffix = ".wal"
+	// walReplayTimeout bounds the export of each replayed batch.
+	walReplayTimeout = 30 * time.Second
+)
+
+// walExporter writes the batches which fail to export to a log on disk,
+// and replays them once an export succeeds, so that spans are not lost
+// while the collector is unreachable. Batches left by a previous process,
+// such as one stopped during a deploy, are replayed too. The log is
+// bounded to maxBytes by dropping the oldest batches.
+//
+// Batches written to the log are reported to the batch span processor as
+// exported.
+type walExporter struct {
+	trace.SpanExporter
+	dir      string
+	maxBytes int64
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu        sync.Mutex
+	seq       uint64
+	replaying bool
+}
+
+func newWALExporter(next trace.SpanExporter, dir string, maxBytes int64) (*walExporter, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultDiskBufferMaxBytes
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("creating disk buffer: %w", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &walExporter{SpanExporter: next, dir: dir, maxBytes: maxBytes, ctx: ctx, cancel: cancel}
+	segs, err := e.segments()
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("reading disk buffer: %w", err)
+	}
+	if len(segs) > 0 {
+		e.seq = segs[len(segs)-1].seq
+	}
+	return e, nil
+}
+
+// walSegment is a file in the log holding a single batch.
+type walSegment struct {
+	seq  uint64
+	path string
+	size int64
+}
+
+// segments returns the segments in the log, oldest first.
+func (e *walExporter) segments() ([]walSegment, error) {
+	infos, err := ioutil.ReadDir(e.dir)
+	if err != nil {
+		return nil, err
+	}
+	var segs []walSegment
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !strings.HasSuffix(name, walSuffix) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, walSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		segs = append(segs, walSegment{seq: seq, path: filepath.Join(e.dir, name), size: info.Size()})
+	}
+	sort.Slice(segs, func(i, j int) bool { return segs[i].seq < segs[j].seq })
+	return segs, nil
+}
+
+func (e *walExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	if err := e.SpanExporter.ExportSpans(ctx, spans); err != nil {
+		if werr := e.write(spans); werr != nil {
+			return multierr.Append(err, fmt.Errorf("writing to disk buffer: %w", werr))
+		}
+		return nil
+	}
+	e.replay()
+	return nil
+}
+
+// write appends spans to the log, then drops the oldest batches if the
+// log exceeds its limit.
+func (e *walExporter) write(spans []trace.ReadOnlySpan) error {
+	b, err := proto.Marshal(&tracepb.TracesData{ResourceSpans: spansToProto(spans)})
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.seq++
+	path := filepath.Join(e.dir, fmt.Sprintf("%020d%s", e.seq, walSuffix))
+	// The segment is renamed into place, so that a partially written
+	// segment is never replayed.
+	if err := ioutil.WriteFile(path+".tmp", b, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+	return e.trim()
+}
+
+// trim removes the oldest segments while the log exceeds its limit.
+func (e *walExporter) trim() error {
+	segs, err := e.segments()
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, seg := range segs {
+		total += seg.size
+	}
+	dropped := 0
+	for ; total > e.maxBytes && len(segs) > 0; segs = segs[1:] {
+		if err := os.Remove(segs[0].path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		total -= segs[0].size
+		dropped++
+	}
+	if dropped > 0 {
+		otel.Handle(DroppedError{fmt.Errorf("disk buffer exceeded %d bytes: dropped the %d oldest batches", e.maxBytes, dropped)})
+	}
+	return nil
+}
+
+// replay starts exporting the batches in the log, oldest first, unless
+// they are already being replayed. Replay stops at the first batch which
+// fails to export.
+func (e *walExporter) replay() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.replaying || e.ctx.Err() != nil {
+		return
+	}
+	segs, err := e.segments()
+	if err != nil || len(segs) == 0 {
+		return
+	}
+	e.replaying = true
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		for _, seg := range segs {
+			if err := e.replaySegment(seg); err != nil {
+				otel.Handle(fmt.Errorf("replaying disk buffer: %w", err))
+				break
+			}
+		}
+		e.mu.Lock()
+		e.replaying = false
+		e.mu.Unlock()
+	}()
+}
+
+func (e *walExporter) replaySegment(seg walSegment) error {
+	b, err := ioutil.ReadFile(seg.path)
+	if os.IsNotExist(err) {
+		// The segment was dropped to keep the log within its limit.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var td tracepb.TracesData
+	if err := proto.Unmarshal(b, &td); err != nil {
+		otel.Handle(DroppedError{fmt.Errorf("dropping corrupt disk buffer segment %s: %w", seg.path, err)})
+		return os.Remove(seg.path)
+	}
+	var spans []trace.ReadOnlySpan
+	for _, rs := range td.GetResourceSpans() {
+		s, err := forwardedSpans(resource.Empty(), rs)
+		if err != nil {
+			return err
+		}
+		spans = append(spans, s...)
+	}
+	ctx, cancel := context.WithTimeout(e.ctx, walReplayTimeout)
+	defer cancel()
+	if err := e.SpanExporter.ExportSpans(ctx, spans); err != nil {
+		return err
+	}
+	if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops any replay, leaving the remaining batches in the log for
+// the next process, and shuts down the wrapped exporter.
+func (e *walExporter) Shutdown(ctx context.Context) error {
+	e.cancel()
+	e.wg.Wait()
+	return e.SpanExporter.Shutdown(ctx)
+}
@@ -0,0 +1,114 @@
+package pipelines
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// flakyExporter fails to export while failing is set.
+type flakyExporter struct {
+	mu      sync.Mutex
+	failing bool
+	*tracetest.InMemoryExporter
+}
+
+func (e *flakyExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.failing {
+		return errors.New("collector unavailable")
+	}
+	return e.InMemoryExporter.ExportSpans(ctx, spans)
+}
+
+func (e *flakyExporter) names() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var names []string
+	for _, s := range e.GetSpans() {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func walSpan(name string) trace.ReadOnlySpan {
+	return tracetest.SpanStub{
+		Name: name,
+		SpanContext: oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+			TraceID: [16]byte{1},
+			SpanID:  [8]byte{2},
+		}),
+		Attributes: []attribute.KeyValue{attribute.Int("attempt", 1)},
+		Resource:   resource.NewSchemaless(attribute.String("service.name", "api")),
+	}.Snapshot()
+}
+
+func TestWALExporter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+
+	next := &flakyExporter{failing: true, InMemoryExporter: tracetest.NewInMemoryExporter()}
+	e, err := newWALExporter(next, dir, 0)
+	require.NoError(t, err)
+	require.NoError(t, e.ExportSpans(ctx, []trace.ReadOnlySpan{walSpan("first")}))
+	require.NoError(t, e.ExportSpans(ctx, []trace.ReadOnlySpan{walSpan("second")}))
+	require.NoError(t, e.Shutdown(ctx))
+	segs, err := e.segments()
+	require.NoError(t, err)
+	assert.Len(t, segs, 2)
+
+	// A new process replays the buffered batches after its first export.
+	next.failing = false
+	e, err = newWALExporter(next, dir, 0)
+	require.NoError(t, err)
+	require.NoError(t, e.ExportSpans(ctx, []trace.ReadOnlySpan{walSpan("third")}))
+	assert.Eventually(t, func() bool { return len(next.names()) == 3 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"third", "first", "second"}, next.names())
+	replayed := next.GetSpans()[1]
+	assert.Equal(t, walSpan("first").SpanContext().TraceID(), replayed.SpanContext.TraceID())
+	assert.Contains(t, replayed.Attributes, attribute.Int("attempt", 1))
+	assert.Contains(t, replayed.Resource.Attributes(), attribute.String("service.name", "api"))
+	require.NoError(t, e.Shutdown(ctx))
+	segs, err = e.segments()
+	require.NoError(t, err)
+	assert.Empty(t, segs)
+}
+
+func TestWALExporterLimit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+
+	next := &flakyExporter{failing: true, InMemoryExporter: tracetest.NewInMemoryExporter()}
+	e, err := newWALExporter(next, dir, 0)
+	require.NoError(t, err)
+	require.NoError(t, e.ExportSpans(ctx, []trace.ReadOnlySpan{walSpan("first")}))
+	segs, err := e.segments()
+	require.NoError(t, err)
+	require.Len(t, segs, 1)
+
+	// Allow room for one and a half batches.
+	e.maxBytes = segs[0].size * 3 / 2
+	require.NoError(t, e.ExportSpans(ctx, []trace.ReadOnlySpan{walSpan("second")}))
+	require.NoError(t, e.ExportSpans(ctx, []trace.ReadOnlySpan{walSpan("third")}))
+	segs, err = e.segments()
+	require.NoError(t, err)
+	require.Len(t, segs, 1)
+	assert.Equal(t, uint64(3), segs[0].seq)
+}
@@ -0,0 +1,30 @@
+package propagators
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestCFBinary(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	carrier := propagation.MapCarrier{}
+	CFBinary{}.Inject(trace.ContextWithSpanContext(context.Background(), sc), carrier)
+	assert.Len(t, carrier[CFBinaryHeader], 35)
+
+	got := trace.SpanContextFromContext(CFBinary{}.Extract(context.Background(), carrier))
+	assert.Equal(t, sc, got)
+
+	for _, v := range []string{"", "not base64!", "AQ", "AUv5LzV3s02mo86SnQ4ORzYA8GeqC6kCtwE"} {
+		ctx := CFBinary{}.Extract(context.Background(), propagation.MapCarrier{CFBinaryHeader: v})
+		assert.False(t, trace.SpanContextFromContext(ctx).IsValid(), v)
+	}
+}
@@ -0,0 +1,57 @@
+// Package sampling provides samplers for use with the launcher which
+// compose the OpenTelemetry SDK samplers.
+package sampling
+
+import (
+	"context"
+	"encoding/binary"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// RemoteParentBased returns a sampler which distinguishes where a span's
+// parent was created. Spans with a remote parent, such as requests from an
+// API gateway, are sampled by remote, which may inspect the parent's
+// sampling decision in the sampling parameters. Spans which are roots of a
+// trace are sampled by root, and spans with a local parent follow the
+// decision of their parent.
+func RemoteParentBased(root, remote trace.Sampler) trace.Sampler {
+	return trace.ParentBased(root,
+		trace.WithRemoteParentSampled(remote),
+		trace.WithRemoteParentNotSampled(remote),
+	)
+}
+
+// TraceIDRatioBasedOnParentRemote returns a sampler which honours the
+// sampling decision of remote parents, and samples traces rooted in this
+// process by trace ID at the given ratio.
+func TraceIDRatioBasedOnParentRemote(ratio float64) trace.Sampler {
+	return RemoteParentBased(trace.TraceIDRatioBased(ratio), ParentDecision())
+}
+
+// ParentDecision returns a sampler which samples a span if and only if its
+// parent is sampled. Spans without a parent are dropped.
+func ParentDecision() trace.Sampler {
+	return trace.ParentBased(trace.NeverSample())
+}
+
+// DeterministicBool reports whether the trace of the span in ctx falls
+// within rate, using the same calculation over the trace ID as
+// trace.TraceIDRatioBased. Every process handling a trace makes the same
+// decision, and with a rate no greater than the trace sampling ratio the
+// traces selected are a subset of the sampled ones, so debug logging and
+// expensive diagnostics can be enabled for requests which have a trace.
+// It returns false if ctx has no valid span context.
+func DeterministicBool(ctx context.Context, rate float64) bool {
+	sc := oteltrace.SpanContextFromContext(ctx)
+	if !sc.TraceID().IsValid() || rate <= 0 {
+		return false
+	}
+	if rate >= 1 {
+		return true
+	}
+	traceID := sc.TraceID()
+	x := binary.BigEndian.Uint64(traceID[0:8]) >> 1
+	return x < uint64(rate*(1<<63))
+}
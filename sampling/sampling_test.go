@@ -0,0 +1,57 @@
+package sampling
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceIDRatioBasedOnParentRemote(t *testing.T) {
+	s := TraceIDRatioBasedOnParentRemote(0)
+	traceID := trace.TraceID{1}
+	parent := func(remote bool, flags trace.TraceFlags) context.Context {
+		sc := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     trace.SpanID{1},
+			TraceFlags: flags,
+			Remote:     remote,
+		})
+		return trace.ContextWithSpanContext(context.Background(), sc)
+	}
+
+	for name, tc := range map[string]struct {
+		ctx  context.Context
+		want sdktrace.SamplingDecision
+	}{
+		"root":               {context.Background(), sdktrace.Drop},
+		"remote sampled":     {parent(true, trace.FlagsSampled), sdktrace.RecordAndSample},
+		"remote not sampled": {parent(true, 0), sdktrace.Drop},
+		"local sampled":      {parent(false, trace.FlagsSampled), sdktrace.RecordAndSample},
+		"local not sampled":  {parent(false, 0), sdktrace.Drop},
+	} {
+		res := s.ShouldSample(sdktrace.SamplingParameters{ParentContext: tc.ctx, TraceID: traceID, Name: "op"})
+		assert.Equal(t, tc.want, res.Decision, name)
+	}
+
+	res := TraceIDRatioBasedOnParentRemote(1).ShouldSample(sdktrace.SamplingParameters{ParentContext: context.Background(), TraceID: traceID})
+	assert.Equal(t, sdktrace.RecordAndSample, res.Decision)
+}
+
+func TestDeterministicBool(t *testing.T) {
+	assert.False(t, DeterministicBool(context.Background(), 1), "no span context")
+
+	ratio := sdktrace.TraceIDRatioBased(0.25)
+	for i := 0; i < 64; i++ {
+		traceID := trace.TraceID{byte(i * 4), byte(i), 15: 1}
+		sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: trace.SpanID{1}})
+		ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+		sampled := ratio.ShouldSample(sdktrace.SamplingParameters{TraceID: traceID}).Decision == sdktrace.RecordAndSample
+		assert.Equal(t, sampled, DeterministicBool(ctx, 0.25), traceID.String())
+		assert.True(t, DeterministicBool(ctx, 1))
+		assert.False(t, DeterministicBool(ctx, 0))
+	}
+}
@@ -0,0 +1,160 @@
+// Package tracing provides helpers for creating spans with consistent
+// defaults across Common Fate services.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/common-fate/observability/tracing"
+
+// Profiler label keys set by Start when profiler labels are enabled.
+const (
+	TraceIDLabel  = "trace_id"
+	SpanNameLabel = "span_name"
+)
+
+var (
+	defaultKind    = int32(trace.SpanKindInternal)
+	kindValidation int32
+	profilerLabels int32
+)
+
+// SetDefaultKind sets the span kind used by Start when WithKind is not
+// given. The initial default is trace.SpanKindInternal.
+func SetDefaultKind(kind trace.SpanKind) {
+	atomic.StoreInt32(&defaultKind, int32(trace.ValidateSpanKind(kind)))
+}
+
+// SetKindValidation enables validation of the kind of each span started
+// with Start against the kind of its parent. Invalid combinations are
+// reported to the global OpenTelemetry error handler. Validation is
+// intended for development, and is enabled by launcher.WithDevMode.
+func SetKindValidation(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&kindValidation, v)
+}
+
+// SetProfilerLabels enables setting the runtime/pprof labels TraceIDLabel
+// and SpanNameLabel on the current goroutine for the duration of each span
+// started with Start, so CPU profiles can be segmented by operation. The
+// labels are restored when the span ends, so the span must be ended on the
+// goroutine which started it. Goroutines started with the returned context
+// via pprof.Do inherit the labels.
+func SetProfilerLabels(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&profilerLabels, v)
+}
+
+type config struct {
+	kind  trace.SpanKind
+	attrs []attribute.KeyValue
+}
+
+// Option configures a span started with Start.
+type Option func(*config)
+
+// WithKind sets the kind of the span.
+func WithKind(kind trace.SpanKind) Option {
+	return func(c *config) {
+		c.kind = kind
+	}
+}
+
+// WithAttributes sets attributes on the span.
+func WithAttributes(attrs ...attribute.KeyValue) Option {
+	return func(c *config) {
+		c.attrs = append(c.attrs, attrs...)
+	}
+}
+
+// Start creates a span using the global tracer provider. The span must be
+// ended by the caller.
+func Start(ctx context.Context, name string, opts ...Option) (context.Context, trace.Span) {
+	c := config{kind: trace.SpanKind(atomic.LoadInt32(&defaultKind))}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if atomic.LoadInt32(&kindValidation) == 1 {
+		if err := validateKind(ctx, name, c.kind); err != nil {
+			otel.Handle(err)
+		}
+	}
+	spanCtx, span := otel.Tracer(instrumentationName).Start(ctx, name,
+		trace.WithSpanKind(c.kind),
+		trace.WithAttributes(c.attrs...),
+	)
+	if atomic.LoadInt32(&profilerLabels) == 1 {
+		return withProfilerLabels(ctx, spanCtx, name, span)
+	}
+	return spanCtx, span
+}
+
+// labelledSpan restores the profiler labels of the parent context when the
+// span ends.
+type labelledSpan struct {
+	trace.Span
+	parent context.Context
+}
+
+func (s labelledSpan) End(options ...trace.SpanEndOption) {
+	s.Span.End(options...)
+	pprof.SetGoroutineLabels(s.parent)
+}
+
+// withProfilerLabels sets the profiler labels for span on the current
+// goroutine.
+func withProfilerLabels(parent, ctx context.Context, name string, span trace.Span) (context.Context, trace.Span) {
+	labels := []string{SpanNameLabel, name}
+	if sc := span.SpanContext(); sc.HasTraceID() {
+		labels = append(labels, TraceIDLabel, sc.TraceID().String())
+	}
+	ctx = pprof.WithLabels(ctx, pprof.Labels(labels...))
+	pprof.SetGoroutineLabels(ctx)
+	span = labelledSpan{Span: span, parent: parent}
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+// validateKind checks that a span of the given kind may be a child of the
+// span in ctx:
+//
+//   - server and consumer spans begin handling a request or message, so
+//     must not have a local parent.
+//   - client and producer spans describe a single outgoing call, so must
+//     not have a local client or producer parent.
+func validateKind(ctx context.Context, name string, kind trace.SpanKind) error {
+	parent := trace.SpanFromContext(ctx)
+	if ls, ok := parent.(labelledSpan); ok {
+		parent = ls.Span
+	}
+	sc := parent.SpanContext()
+	if !sc.IsValid() || sc.IsRemote() {
+		return nil
+	}
+	switch kind {
+	case trace.SpanKindServer, trace.SpanKindConsumer:
+		return fmt.Errorf("span %q has kind %s but a local parent span: %s spans should only follow remote parents", name, kind, kind)
+	case trace.SpanKindClient, trace.SpanKindProducer:
+		k, ok := parent.(interface{ SpanKind() trace.SpanKind })
+		if !ok {
+			return nil
+		}
+		if pk := k.SpanKind(); pk == trace.SpanKindClient || pk == trace.SpanKindProducer {
+			return fmt.Errorf("span %q has kind %s but its parent has kind %s: outgoing calls should not be nested", name, kind, pk)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestValidateKind(t *testing.T) {
+	tracer := sdktrace.NewTracerProvider().Tracer("test")
+	remote := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    [16]byte{1},
+		SpanID:     [8]byte{1},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	}))
+	serverCtx, server := tracer.Start(remote, "server", trace.WithSpanKind(trace.SpanKindServer))
+	defer server.End()
+	clientCtx, client := tracer.Start(serverCtx, "client", trace.WithSpanKind(trace.SpanKindClient))
+	defer client.End()
+
+	assert.NoError(t, validateKind(context.Background(), "root", trace.SpanKindServer))
+	assert.NoError(t, validateKind(remote, "server", trace.SpanKindServer))
+	assert.Error(t, validateKind(serverCtx, "nested server", trace.SpanKindServer))
+	assert.NoError(t, validateKind(serverCtx, "client", trace.SpanKindClient))
+	assert.Error(t, validateKind(clientCtx, "nested client", trace.SpanKindClient))
+	assert.NoError(t, validateKind(clientCtx, "internal", trace.SpanKindInternal))
+}
+
+func TestProfilerLabels(t *testing.T) {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+	SetProfilerLabels(true)
+	defer SetProfilerLabels(false)
+
+	ctx, span := Start(context.Background(), "parent")
+	name, _ := pprof.Label(ctx, SpanNameLabel)
+	assert.Equal(t, "parent", name)
+	traceID, _ := pprof.Label(ctx, TraceIDLabel)
+	assert.Equal(t, span.SpanContext().TraceID().String(), traceID)
+
+	childCtx, child := Start(ctx, "child")
+	name, _ = pprof.Label(childCtx, SpanNameLabel)
+	assert.Equal(t, "child", name)
+	assert.Equal(t, span.SpanContext().TraceID(), child.SpanContext().TraceID())
+	assert.Equal(t, child, trace.SpanFromContext(childCtx))
+	child.End()
+	span.End()
+}